@@ -2,14 +2,14 @@ package calibrated
 
 import (
 	"context"
-	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/trickstertwo/xclock"
 )
 
-// Calibrated clock: applies a dynamic offset (delta) to base.Now().
+// Calibrated clock: applies a dynamic offset (delta) to base.Now(), optionally
+// extrapolated between syncs from the estimated frequency error (see Drift).
 // Typical use: learn delta from an external authority (NTP, secure time, GPS).
 // No background goroutines unless StartAutoSync is used.
 
@@ -18,6 +18,16 @@ type Config struct {
 	Base xclock.Clock
 	// InitialOffset is an optional offset to apply immediately.
 	InitialOffset time.Duration
+	// StaleAfter marks the clock Degraded when no sync succeeded for this long.
+	// 0 disables it. See SetStaleAfter.
+	StaleAfter time.Duration
+	// OnSync and OnError, if set, are called after each successful or failed sync.
+	OnSync  func(Status)
+	OnError func(error, Status)
+	// Sampling configures SyncOnce; see SetSampling. Defaults to one sample.
+	Sampling Sampling
+	// Drift enables frequency estimation; see SetDrift. Disabled by default.
+	Drift Drift
 }
 
 // Set sets the calibrated clock as the process-wide default and returns a restore
@@ -28,22 +38,14 @@ type Config struct {
 //	defer restore()
 func Set(cfg Config) (restore func()) {
 	prev := xclock.Default()
-	c := New(cfg.Base)
-	if cfg.InitialOffset != 0 {
-		c.SetOffset(cfg.InitialOffset)
-	}
-	xclock.SetDefault(c)
+	xclock.SetDefault(fromConfig(cfg))
 	return func() { xclock.SetDefault(prev) }
 }
 
 // Use applies the calibrated clock without returning a restore function.
 // Recommended in production mains where you never intend to restore.
 func Use(cfg Config) {
-	c := New(cfg.Base)
-	if cfg.InitialOffset != 0 {
-		c.SetOffset(cfg.InitialOffset)
-	}
-	xclock.SetDefault(c)
+	xclock.SetDefault(fromConfig(cfg))
 }
 
 // With runs fn with the calibrated clock active, then restores the previous clock
@@ -55,21 +57,49 @@ func With(cfg Config, fn func()) {
 }
 
 type Clock struct {
-	base  xclock.Clock
-	delta atomic.Int64 // nanoseconds to add to base.Now()
+	base     xclock.Clock
+	delta    atomic.Int64 // nanoseconds to add to base.Now()
+	wall     xclock.WallTimers
+	health   health
+	sampling atomic.Pointer[Sampling]
+	stepout  stepout
+	drift    drift
+	pred     atomic.Pointer[prediction] // nil: delta is constant
 }
 
 func New(base xclock.Clock) *Clock {
 	if base == nil {
 		base = xclock.Default()
 	}
-	return &Clock{base: base}
+	c := &Clock{base: base}
+	c.health.ref = base.Now()
+	return c
+}
+
+func fromConfig(cfg Config) *Clock {
+	c := New(cfg.Base)
+	if cfg.InitialOffset != 0 {
+		c.SetOffset(cfg.InitialOffset)
+	}
+	c.health.staleAfter = max(cfg.StaleAfter, 0)
+	c.health.onSync, c.health.onError = cfg.OnSync, cfg.OnError
+	c.SetSampling(cfg.Sampling)
+	c.SetDrift(cfg.Drift)
+	return c
 }
 
-// Now returns base.Now() + current offset (delta).
+// Now returns base.Now() + current offset (delta), extrapolated by the
+// estimated frequency error when Drift is enabled.
 func (c *Clock) Now() time.Time {
-	d := time.Duration(c.delta.Load())
-	return c.base.Now().Add(d)
+	now := c.base.Now()
+	return now.Add(c.offsetAt(now))
+}
+
+func (c *Clock) offsetAt(now time.Time) time.Duration {
+	if p := c.pred.Load(); p != nil {
+		return p.offsetAt(now)
+	}
+	return time.Duration(c.delta.Load())
 }
 
 func (c *Clock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
@@ -83,57 +113,53 @@ func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
 func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
 func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
 
-// SetOffset sets the absolute delta to apply to base time.
-func (c *Clock) SetOffset(d time.Duration) { c.delta.Store(int64(d)) }
+// NewTimerAt returns a timer firing when the calibrated Now() reaches t.
+// Pending wall timers re-arm whenever the offset changes (SetOffset, SyncOnce).
+func (c *Clock) NewTimerAt(t time.Time) xclock.Timer { return c.wall.NewTimerAt(c, t) }
+
+// AfterFuncAt runs f when the calibrated Now() reaches t. See NewTimerAt.
+func (c *Clock) AfterFuncAt(t time.Time, f func()) xclock.CancelFunc {
+	return c.wall.AfterFuncAt(c, t, f)
+}
+
+// SetOffset sets the absolute delta to apply to base time. It clears the drift
+// history and estimate.
+func (c *Clock) SetOffset(d time.Duration) {
+	c.drift.mu.Lock()
+	c.freezeLocked()
+	c.delta.Store(int64(d))
+	c.drift.mu.Unlock()
+	c.wall.Rearm()
+}
 
-// AdjustOffset adds d to the existing delta.
-func (c *Clock) AdjustOffset(d time.Duration) { c.delta.Add(int64(d)) }
+// AdjustOffset adds d to the existing delta. It clears the drift history and
+// estimate, keeping the offset predicted at the time of the call.
+func (c *Clock) AdjustOffset(d time.Duration) {
+	c.drift.mu.Lock()
+	c.freezeLocked()
+	c.delta.Add(int64(d))
+	c.drift.mu.Unlock()
+	c.wall.Rearm()
+}
 
-// Offset returns the current delta.
-func (c *Clock) Offset() time.Duration { return time.Duration(c.delta.Load()) }
+// Offset returns the current delta, including predicted drift.
+func (c *Clock) Offset() time.Duration { return c.offsetAt(c.base.Now()) }
 
 // SyncOnce uses fetch(ctx) → authoritative time to compute delta.
-// delta = authoritative - base.Now(). Best-effort; leaves the previous delta on error.
+// delta = authoritative - base.Now(), from the best of the configured samples
+// (see Sampling). Best-effort; leaves the previous delta on error. The outcome is
+// recorded in Status and reported to OnSync/OnError; Sync also returns details.
 func (c *Clock) SyncOnce(ctx context.Context, fetch func(context.Context) (time.Time, error)) error {
-	t, err := fetch(ctx)
-	if err != nil {
-		return err
-	}
-	now := c.base.Now()
-	c.SetOffset(t.Sub(now))
-	return nil
+	_, err := c.Sync(ctx, fetch)
+	return err
 }
 
-// StartAutoSync starts a periodic calibration loop. Returns a cancel function
-// that is safe to call multiple times (idempotent). Uses the provided scheduler
-// for ticks (defaults to c.base).
+// StartAutoSync starts a periodic calibration loop that syncs immediately and
+// retries failures with backoff; see AutoSync. Returns a cancel function that is
+// safe to call multiple times (idempotent). Uses the provided scheduler for
+// ticks (defaults to c.base). Errors are reported through Status and OnError.
 func (c *Clock) StartAutoSync(ctx context.Context, period time.Duration, fetch func(context.Context) (time.Time, error), sched xclock.Clock) (cancel func()) {
-	if period <= 0 {
-		// No-op cancel for invalid period, keeps API safe.
-		return func() {}
-	}
-	if sched == nil {
-		sched = c.base
-	}
-	stop := make(chan struct{})
-	var once sync.Once
-
-	go func() {
-		tk := sched.NewTicker(period)
-		defer tk.Stop()
-		for {
-			select {
-			case <-tk.C():
-				_ = c.SyncOnce(ctx, fetch) // best-effort
-			case <-stop:
-				return
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
-
-	return func() {
-		once.Do(func() { close(stop) })
-	}
+	// An invalid period yields a no-op cancel, keeping the API safe.
+	cancel, _ = c.AutoSync(ctx, AutoSyncConfig{Period: period, Fetch: fetch, Scheduler: sched})
+	return cancel
 }
@@ -0,0 +1,153 @@
+package calibrated
+
+import (
+	"sync"
+	"time"
+)
+
+// Drift estimation: the offset measured by successive syncs changes steadily when
+// the base clock's oscillator runs fast or slow. With Drift enabled, a line is
+// fitted through the last Window measured offsets (least squares over base time)
+// and Now() extrapolates it between syncs instead of holding the offset constant.
+//
+// Notes:
+// - The prediction is anchored at the latest measurement: a sync still sets the
+//   offset to what it measured, and only the slope comes from the fit.
+// - PPM is the rate at which the offset grows, in parts per million: positive
+//   when the base clock runs slow relative to the authority.
+// - SetOffset and AdjustOffset are manual steps; they clear the history and the
+//   estimate. Rejected steps (see Sampling.MaxStep) are not recorded, and an
+//   accepted one restarts the fit from the new offset: a step inside the window
+//   would otherwise read as a huge frequency error until it aged out.
+// - Now() stays lock-free: the prediction is one atomic pointer.
+
+// Drift configures frequency estimation.
+type Drift struct {
+	// Window is the number of recent sync offsets fitted. Values below 2 disable
+	// estimation.
+	Window int
+	// MaxPPM bounds the estimate. Defaults to 500.
+	MaxPPM float64
+	// StepThreshold is how far a measured offset may be from the prediction before
+	// it counts as a step and restarts the fit. Defaults to Sampling.MaxStep, or
+	// 100ms when that is 0.
+	StepThreshold time.Duration
+}
+
+// prediction extrapolates the offset measured at base time at.
+type prediction struct {
+	offset time.Duration
+	at     time.Time
+	ppm    float64
+}
+
+func (p *prediction) offsetAt(now time.Time) time.Duration {
+	return p.offset + time.Duration(float64(now.Sub(p.at))*p.ppm/1e6)
+}
+
+type point struct {
+	at     time.Time
+	offset time.Duration
+}
+
+// drift holds the offset history behind the prediction.
+type drift struct {
+	mu     sync.Mutex
+	cfg    Drift
+	points []point
+}
+
+// SetDrift sets frequency estimation; see Drift. It clears the history.
+func (c *Clock) SetDrift(d Drift) {
+	if d.MaxPPM <= 0 {
+		d.MaxPPM = 500
+	}
+	c.drift.mu.Lock()
+	c.drift.cfg = d
+	c.freezeLocked()
+	c.drift.mu.Unlock()
+}
+
+// Drift returns the frequency estimation configuration.
+func (c *Clock) Drift() Drift {
+	c.drift.mu.Lock()
+	defer c.drift.mu.Unlock()
+	return c.drift.cfg
+}
+
+// PPM returns the estimated frequency error applied by Now(), or 0 without an
+// estimate.
+func (c *Clock) PPM() float64 {
+	if p := c.pred.Load(); p != nil {
+		return p.ppm
+	}
+	return 0
+}
+
+// observe applies an offset measured by a sync at base time at, updating the
+// frequency estimate.
+func (c *Clock) observe(offset time.Duration, at time.Time) {
+	c.drift.mu.Lock()
+	step := c.drift.cfg.StepThreshold
+	if step <= 0 {
+		step = c.Sampling().MaxStep
+	}
+	if step <= 0 {
+		step = 100 * time.Millisecond
+	}
+	if len(c.drift.points) > 0 && (offset-c.offsetAt(at)).Abs() > step {
+		c.drift.points = nil
+	}
+	ppm, ok := c.drift.addLocked(point{at: at, offset: offset})
+	c.delta.Store(int64(offset))
+	if ok {
+		c.pred.Store(&prediction{offset: offset, at: at, ppm: ppm})
+	} else {
+		c.pred.Store(nil)
+	}
+	c.drift.mu.Unlock()
+	c.wall.Rearm()
+}
+
+// freezeLocked drops the history and keeps the currently predicted offset
+// constant. The caller holds c.drift.mu, so a concurrent sync cannot install a
+// prediction between the freeze and the caller's own change of delta.
+func (c *Clock) freezeLocked() {
+	c.drift.points = nil
+	if p := c.pred.Swap(nil); p != nil {
+		c.delta.Store(int64(p.offsetAt(c.base.Now())))
+	}
+}
+
+// addLocked records p and returns the fitted slope in ppm, if there is one.
+func (d *drift) addLocked(p point) (float64, bool) {
+	if d.cfg.Window < 2 {
+		return 0, false
+	}
+	d.points = append(d.points, p)
+	if n := len(d.points); n > d.cfg.Window {
+		d.points = append(d.points[:0], d.points[n-d.cfg.Window:]...)
+	}
+	if len(d.points) < 2 {
+		return 0, false
+	}
+	// Least squares of offset over time, both in seconds relative to the oldest
+	// point to keep the sums well conditioned.
+	t0 := d.points[0].at
+	var sx, sy, sxx, sxy float64
+	for _, q := range d.points {
+		x := q.at.Sub(t0).Seconds()
+		y := q.offset.Seconds()
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	n := float64(len(d.points))
+	den := n*sxx - sx*sx
+	if den <= 0 {
+		return 0, false
+	}
+	ppm := (n*sxy - sx*sy) / den * 1e6
+	return min(max(ppm, -d.cfg.MaxPPM), d.cfg.MaxPPM), true
+}
@@ -0,0 +1,234 @@
+package httptime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// HTTP time: derives authoritative time from HTTP servers for
+// calibrated.SyncOnce, for networks that block NTP (UDP/123) but allow HTTPS.
+// A Fetcher sends a few requests, keeps the one with the smallest round trip and
+// assumes the server read its clock at the round trip's midpoint.
+//
+// Notes:
+// - Date mode reads the standard Date header, which any web server sets but only
+//   to the second; the time is centred in its second (+500ms), so the error is
+//   up to ±500ms plus half the round trip.
+// - JSON mode reads {"unix_nano": ...} from an endpoint served by Handler (or
+//   anything compatible) and is limited only by network asymmetry.
+// - Responses carrying a non-zero Age header come from a cache and are skipped.
+// - Fetch returns the estimate advanced to the moment it returns, so it can be
+//   passed to SyncOnce directly:
+//
+//	f := httptime.New(httptime.Config{URL: "https://example.com/"})
+//	err := c.SyncOnce(ctx, f.Fetch)
+
+// Mode selects how server time is read from the response.
+type Mode int
+
+const (
+	// Date reads the Date response header (one-second resolution).
+	Date Mode = iota
+	// JSON reads a JSON body with nanosecond Unix time, as served by Handler.
+	JSON
+)
+
+type Config struct {
+	// URL is the server to query.
+	URL string
+	// Mode selects Date header or JSON body. Defaults to Date.
+	Mode Mode
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Samples is the number of requests per Fetch; the one with the smallest round
+	// trip wins. Defaults to 4.
+	Samples int
+	// Clock measures round trips. It should be monotonic and is not the clock
+	// being calibrated. If nil, xclock.System() is used.
+	Clock xclock.Clock
+}
+
+// Sample is one measurement.
+type Sample struct {
+	// Server is the server's time, estimated at the round trip's midpoint.
+	Server time.Time
+	// RTT is the round-trip time of the request.
+	RTT time.Duration
+	// Local is the local clock's time at the round trip's midpoint.
+	Local time.Time
+}
+
+// Offset returns the server's time minus the local clock's.
+func (s Sample) Offset() time.Duration { return s.Server.Sub(s.Local) }
+
+// ErrNoSample is returned (wrapped) when every request of a Fetch failed.
+var ErrNoSample = errors.New("httptime: no usable sample")
+
+type Fetcher struct {
+	url     string
+	mode    Mode
+	client  *http.Client
+	samples int
+	clk     xclock.Clock
+}
+
+// New constructs a Fetcher.
+func New(cfg Config) *Fetcher {
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.Samples <= 0 {
+		cfg.Samples = 4
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = xclock.System()
+	}
+	return &Fetcher{url: cfg.URL, mode: cfg.Mode, client: cfg.Client, samples: cfg.Samples, clk: cfg.Clock}
+}
+
+// Fetch returns the server's current time, estimated from the sample with the
+// smallest round trip. Its signature matches calibrated.Clock.SyncOnce.
+func (f *Fetcher) Fetch(ctx context.Context) (time.Time, error) {
+	best, err := f.Best(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return best.Server.Add(f.clk.Since(best.Local)), nil
+}
+
+// Best takes the configured number of samples and returns the one with the
+// smallest round trip. Failed requests are skipped; if all fail, the last error
+// is returned wrapped with ErrNoSample.
+func (f *Fetcher) Best(ctx context.Context) (Sample, error) {
+	var (
+		best    Sample
+		ok      bool
+		lastErr error
+	)
+	for range f.samples {
+		s, err := f.Sample(ctx)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if !ok || s.RTT < best.RTT {
+			best, ok = s, true
+		}
+	}
+	if !ok {
+		return Sample{}, fmt.Errorf("%w: %w", ErrNoSample, lastErr)
+	}
+	return best, nil
+}
+
+// Sample performs a single request.
+func (f *Fetcher) Sample(ctx context.Context) (Sample, error) {
+	method := http.MethodHead
+	if f.mode == JSON {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, f.url, nil)
+	if err != nil {
+		return Sample{}, err
+	}
+	req.Header.Set("Cache-Control", "no-cache")
+	if f.mode == JSON {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	t0 := f.clk.Now()
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Sample{}, err
+	}
+	defer resp.Body.Close()
+	var server time.Time
+	if f.mode == JSON {
+		// The body is part of the round trip: the server writes the time into it.
+		server, err = readJSON(resp)
+	} else {
+		server, err = readDate(resp)
+	}
+	rtt := f.clk.Since(t0)
+	if err != nil {
+		return Sample{}, err
+	}
+	if age := resp.Header.Get("Age"); age != "" && age != "0" {
+		return Sample{}, fmt.Errorf("httptime: cached response (Age: %s)", age)
+	}
+	return Sample{Server: server, RTT: rtt, Local: t0.Add(rtt / 2)}, nil
+}
+
+func readDate(resp *http.Response) (time.Time, error) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	h := resp.Header.Get("Date")
+	if h == "" {
+		return time.Time{}, errors.New("httptime: response has no Date header")
+	}
+	t, err := http.ParseTime(h)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("httptime: bad Date header %q: %w", h, err)
+	}
+	return t.Add(500 * time.Millisecond), nil
+}
+
+// response is the JSON body served by Handler.
+type response struct {
+	UnixNano int64  `json:"unix_nano"`
+	Time     string `json:"time,omitempty"`
+}
+
+func readJSON(resp *http.Response) (time.Time, error) {
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("httptime: %s", resp.Status)
+	}
+	var r response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<10)).Decode(&r); err != nil {
+		return time.Time{}, fmt.Errorf("httptime: bad JSON body: %w", err)
+	}
+	if r.UnixNano == 0 {
+		return time.Time{}, errors.New("httptime: JSON body has no unix_nano")
+	}
+	return time.Unix(0, r.UnixNano).UTC(), nil
+}
+
+// Handler serves clk's time for JSON mode and sets the Date header from it for
+// Date mode. GET returns {"unix_nano": <int>, "time": "<RFC 3339>"}; HEAD returns
+// headers only. Responses are marked uncacheable. If clk is nil, xclock.Default()
+// is used at each request.
+func Handler(clk xclock.Clock) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		c := clk
+		if c == nil {
+			c = xclock.Default()
+		}
+		now := c.Now()
+		h := w.Header()
+		h.Set("Cache-Control", "no-store")
+		h.Set("Date", now.UTC().Format(http.TimeFormat))
+		h.Set("Content-Type", "application/json")
+		body, _ := json.Marshal(response{UnixNano: now.UnixNano(), Time: now.UTC().Format(time.RFC3339Nano)})
+		body = append(body, '\n')
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+		if r.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write(body)
+	})
+}
@@ -0,0 +1,136 @@
+package httptime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/trickstertwo/xclock/adapter/frozen"
+)
+
+var serverTime = time.Date(2030, 1, 2, 3, 4, 5, 250_000_000, time.UTC)
+
+func newServer(t *testing.T, h http.Handler) string {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// numbered serves Handler at serverTime + i seconds for the i-th request (from
+// 0), after letting f adjust the response.
+func numbered(f func(i int, w http.ResponseWriter)) http.Handler {
+	var n atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1) - 1)
+		f(i, w)
+		Handler(frozen.New(serverTime.Add(time.Duration(i)*time.Second))).ServeHTTP(w, r)
+	})
+}
+
+func TestJSONMode(t *testing.T) {
+	addr := newServer(t, Handler(frozen.New(serverTime)))
+	f := New(Config{URL: addr, Mode: JSON, Samples: 2})
+	s, err := f.Best(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Server.Equal(serverTime) {
+		t.Fatalf("server = %v, want %v", s.Server, serverTime)
+	}
+	if s.RTT <= 0 {
+		t.Fatalf("rtt = %v, want > 0", s.RTT)
+	}
+	got, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Sub(serverTime); d < 0 || d > time.Second {
+		t.Fatalf("Fetch = %v, want just after %v", got, serverTime)
+	}
+}
+
+func TestDateMode(t *testing.T) {
+	addr := newServer(t, Handler(frozen.New(serverTime)))
+	s, err := New(Config{URL: addr, Samples: 1}).Best(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The Date header has whole seconds; the estimate is centred in the second.
+	want := serverTime.Truncate(time.Second).Add(500 * time.Millisecond)
+	if !s.Server.Equal(want) {
+		t.Fatalf("server = %v, want %v", s.Server, want)
+	}
+}
+
+func TestBestPicksLowestRTT(t *testing.T) {
+	addr := newServer(t, numbered(func(i int, _ http.ResponseWriter) {
+		if i != 2 {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	s, err := New(Config{URL: addr, Mode: JSON, Samples: 4}).Best(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := serverTime.Add(2 * time.Second); !s.Server.Equal(want) {
+		t.Fatalf("server = %v, want the fast third sample %v", s.Server, want)
+	}
+	if s.RTT >= 50*time.Millisecond {
+		t.Fatalf("rtt = %v, want the fast sample's", s.RTT)
+	}
+}
+
+func TestSkipsCachedResponses(t *testing.T) {
+	addr := newServer(t, numbered(func(i int, w http.ResponseWriter) {
+		if i != 3 {
+			w.Header().Set("Age", "5")
+		}
+	}))
+	f := New(Config{URL: addr, Mode: JSON, Samples: 4})
+	if _, err := f.Sample(context.Background()); err == nil || !strings.Contains(err.Error(), "cached") {
+		t.Fatalf("error = %v, want a cached response error", err)
+	}
+	s, err := f.Best(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Of requests 1 to 4, only request 3 is uncached.
+	if want := serverTime.Add(3 * time.Second); !s.Server.Equal(want) {
+		t.Fatalf("server = %v, want the uncached sample %v", s.Server, want)
+	}
+}
+
+func TestNoSample(t *testing.T) {
+	srv := httptest.NewServer(Handler(nil))
+	addr := srv.URL
+	srv.Close()
+	_, err := New(Config{URL: addr, Mode: JSON, Samples: 3}).Fetch(context.Background())
+	if !errors.Is(err, ErrNoSample) {
+		t.Fatalf("error = %v, want ErrNoSample", err)
+	}
+	var uerr *url.Error
+	if !errors.As(err, &uerr) {
+		t.Fatalf("error = %v, want the last request error wrapped", err)
+	}
+}
+
+func TestJSONStatus(t *testing.T) {
+	addr := newServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	f := New(Config{URL: addr, Mode: JSON, Samples: 2})
+	_, err := f.Sample(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("error = %v, want the 503 status", err)
+	}
+	if _, err := f.Best(context.Background()); !errors.Is(err, ErrNoSample) || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("error = %v, want ErrNoSample wrapping the status", err)
+	}
+}
@@ -0,0 +1,297 @@
+package roughtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Roughtime: authenticated calibration source for calibrated.SyncOnce. Each
+// query carries a fresh random nonce; the server's reply is signed by a
+// delegated key, which is itself signed by the server's long-term Ed25519 key,
+// and proves inclusion of the nonce in the signed Merkle root. A network attacker
+// can delay or drop replies but not forge or replay them.
+//
+// Notes:
+// - Implements the original (Google) Roughtime protocol over UDP.
+// - The server's time is taken at the local round trip's midpoint; the true
+//   time is within Radius + RTT/2 of it (Result.Uncertainty). Set MaxUncertainty
+//   to reject replies that are too loose.
+// - Datagrams that fail verification are ignored until the attempt times out,
+//   so injected garbage cannot make a query fail early.
+// - Server is a minimal responder (batch size one) for tests and internal use.
+//
+//	c := calibrated.New(nil)
+//	rt := roughtime.New(roughtime.Config{Address: "roughtime.example:2002", PublicKey: pub})
+//	err := c.SyncOnce(ctx, rt.Fetch)
+
+type Config struct {
+	// Address is the server's host:port.
+	Address string
+	// PublicKey is the server's long-term Ed25519 public key.
+	PublicKey ed25519.PublicKey
+	// Timeout bounds each attempt. Defaults to 1s.
+	Timeout time.Duration
+	// Attempts is the number of queries sent before giving up. Defaults to 3.
+	Attempts int
+	// MaxUncertainty rejects replies whose Uncertainty exceeds it. 0 disables.
+	MaxUncertainty time.Duration
+	// Clock measures round trips. It should be monotonic and is not the clock
+	// being calibrated. If nil, xclock.System() is used.
+	Clock xclock.Clock
+	// Dial opens the connection. Defaults to a net.Dialer dialing "udp".
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Result is a verified reply.
+type Result struct {
+	// Midpoint is the server's time, taken at the local round trip's midpoint.
+	Midpoint time.Time
+	// Radius is the server's claimed uncertainty of Midpoint.
+	Radius time.Duration
+	// RTT is the round-trip time of the query.
+	RTT time.Duration
+	// Local is the local clock's time at the round trip's midpoint.
+	Local time.Time
+}
+
+// Offset returns the server's time minus the local clock's.
+func (r Result) Offset() time.Duration { return r.Midpoint.Sub(r.Local) }
+
+// Uncertainty bounds the error of Midpoint as seen by the client: the server's
+// radius plus half the round trip.
+func (r Result) Uncertainty() time.Duration { return r.Radius + r.RTT/2 }
+
+// ErrTimeout is returned (wrapped) when no verified reply arrived in any attempt.
+var ErrTimeout = errors.New("roughtime: no verified reply")
+
+type Client struct {
+	addr     string
+	pub      ed25519.PublicKey
+	timeout  time.Duration
+	attempts int
+	maxUnc   time.Duration
+	clk      xclock.Clock
+	dial     func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// New constructs a Roughtime client.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = 3
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = xclock.System()
+	}
+	if cfg.Dial == nil {
+		var d net.Dialer
+		cfg.Dial = d.DialContext
+	}
+	return &Client{
+		addr:     cfg.Address,
+		pub:      cfg.PublicKey,
+		timeout:  cfg.Timeout,
+		attempts: cfg.Attempts,
+		maxUnc:   cfg.MaxUncertainty,
+		clk:      cfg.Clock,
+		dial:     cfg.Dial,
+	}
+}
+
+// Fetch returns the server's current time from a verified reply. Its signature
+// matches calibrated.Clock.SyncOnce.
+func (c *Client) Fetch(ctx context.Context) (time.Time, error) {
+	r, err := c.Query(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return r.Midpoint.Add(c.clk.Since(r.Local)), nil
+}
+
+// Query sends up to Attempts queries and returns the first verified reply.
+func (c *Client) Query(ctx context.Context) (Result, error) {
+	if len(c.pub) != ed25519.PublicKeySize {
+		return Result{}, errors.New("roughtime: invalid public key")
+	}
+	conn, err := c.dial(ctx, "udp", c.addr)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	var lastErr error = ErrTimeout
+	for range c.attempts {
+		r, err := c.attempt(conn)
+		if err == nil {
+			if c.maxUnc > 0 && r.Uncertainty() > c.maxUnc {
+				lastErr = fmt.Errorf("roughtime: uncertainty %v exceeds %v", r.Uncertainty(), c.maxUnc)
+				continue
+			}
+			return r, nil
+		}
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		lastErr = err
+	}
+	if errors.Is(lastErr, ErrTimeout) {
+		return Result{}, lastErr
+	}
+	return Result{}, fmt.Errorf("%w: %w", ErrTimeout, lastErr)
+}
+
+// attempt sends one query and reads until a reply verifies or the attempt times
+// out. The error is the last verification failure, or ErrTimeout.
+func (c *Client) attempt(conn net.Conn) (Result, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return Result{}, err
+	}
+	req := newRequest(nonce)
+
+	// Deadlines are real time; the attempt timeout is a socket bound, not
+	// something to simulate.
+	if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return Result{}, err
+	}
+	t0 := c.clk.Now()
+	if _, err := conn.Write(req); err != nil {
+		return Result{}, err
+	}
+	buf := make([]byte, 4096)
+	var lastErr error = ErrTimeout
+	for {
+		n, err := conn.Read(buf)
+		rtt := c.clk.Since(t0)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				return Result{}, lastErr
+			}
+			return Result{}, err
+		}
+		midp, radius, err := verify(c.pub, nonce, buf[:n])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return Result{Midpoint: midp, Radius: radius, RTT: rtt, Local: t0.Add(rtt / 2)}, nil
+	}
+}
+
+func newRequest(nonce []byte) []byte {
+	// Header (4 + 4 + 8) + nonce + padding = requestSize.
+	pad := bytes.Repeat([]byte{0}, requestSize-16-nonceSize)
+	return encode(map[tag][]byte{tagNONC: nonce, tagPAD: pad})
+}
+
+// verify checks a reply against the server's long-term key and the nonce sent,
+// returning the signed midpoint and radius.
+func verify(rootKey ed25519.PublicKey, nonce, reply []byte) (time.Time, time.Duration, error) {
+	msg, err := decode(reply)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	// Delegation: the long-term key vouches for a short-lived online key.
+	certRaw, ok := msg[tagCERT]
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("roughtime: missing tag %v", tagCERT)
+	}
+	cert, err := decode(certRaw)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	certSig, err := fixed(cert, tagSIG, ed25519.SignatureSize)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	deleRaw, ok := cert[tagDELE]
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("roughtime: missing tag %v", tagDELE)
+	}
+	if !ed25519.Verify(rootKey, append(append([]byte(nil), delegationContext...), deleRaw...), certSig) {
+		return time.Time{}, 0, errors.New("roughtime: bad delegation signature")
+	}
+	dele, err := decode(deleRaw)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	pubk, err := fixed(dele, tagPUBK, ed25519.PublicKeySize)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	mint, err := fixed(dele, tagMINT, 8)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	maxt, err := fixed(dele, tagMAXT, 8)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	// Signed response: the online key signs the midpoint, radius and Merkle root.
+	sig, err := fixed(msg, tagSIG, ed25519.SignatureSize)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	srepRaw, ok := msg[tagSREP]
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("roughtime: missing tag %v", tagSREP)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pubk), append(append([]byte(nil), responseContext...), srepRaw...), sig) {
+		return time.Time{}, 0, errors.New("roughtime: bad response signature")
+	}
+	srep, err := decode(srepRaw)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	root, err := fixed(srep, tagROOT, hashSize)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	midpRaw, err := fixed(srep, tagMIDP, 8)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	radiRaw, err := fixed(srep, tagRADI, 4)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	// Inclusion: our nonce is a leaf of the signed tree.
+	indx, err := fixed(msg, tagINDX, 4)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	got, err := merkleRoot(nonce, msg[tagPATH], binary.LittleEndian.Uint32(indx))
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if !bytes.Equal(got, root) {
+		return time.Time{}, 0, errors.New("roughtime: nonce not in signed Merkle root")
+	}
+
+	midp := binary.LittleEndian.Uint64(midpRaw)
+	if midp < binary.LittleEndian.Uint64(mint) || midp > binary.LittleEndian.Uint64(maxt) {
+		return time.Time{}, 0, errors.New("roughtime: midpoint outside delegation validity")
+	}
+	if midp > uint64(1<<63-1)/1000 {
+		return time.Time{}, 0, errors.New("roughtime: midpoint out of range")
+	}
+	radius := time.Duration(binary.LittleEndian.Uint32(radiRaw)) * time.Microsecond
+	return time.UnixMicro(int64(midp)).UTC(), radius, nil
+}
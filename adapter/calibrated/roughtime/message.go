@@ -0,0 +1,169 @@
+package roughtime
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Wire format (Roughtime, original Google protocol): a message is a uint32 tag
+// count n, n-1 uint32 value offsets relative to the end of the header, n uint32
+// tags in strictly ascending order, then the values. Everything is little endian
+// and every offset and length is a multiple of four.
+
+type tag uint32
+
+func mkTag(s string) tag { return tag(binary.LittleEndian.Uint32([]byte(s))) }
+
+var (
+	tagSIG  = mkTag("SIG\x00")
+	tagNONC = mkTag("NONC")
+	tagPAD  = mkTag("PAD\xff")
+	tagPATH = mkTag("PATH")
+	tagSREP = mkTag("SREP")
+	tagCERT = mkTag("CERT")
+	tagINDX = mkTag("INDX")
+	tagRADI = mkTag("RADI")
+	tagMIDP = mkTag("MIDP")
+	tagROOT = mkTag("ROOT")
+	tagDELE = mkTag("DELE")
+	tagMINT = mkTag("MINT")
+	tagMAXT = mkTag("MAXT")
+	tagPUBK = mkTag("PUBK")
+)
+
+func (t tag) String() string {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(t))
+	return fmt.Sprintf("%q", b[:])
+}
+
+// Signature contexts, prepended to the signed message.
+var (
+	responseContext   = []byte("RoughTime v1 response signature\x00")
+	delegationContext = []byte("RoughTime v1 delegation signature--\x00")
+)
+
+const (
+	nonceSize   = 64
+	hashSize    = 64 // SHA-512
+	requestSize = 1024
+)
+
+var errMalformed = errors.New("roughtime: malformed message")
+
+// encode serializes msg; every value length must be a multiple of four.
+func encode(msg map[tag][]byte) []byte {
+	tags := make([]tag, 0, len(msg))
+	size := 4
+	for t, v := range msg {
+		tags = append(tags, t)
+		size += 8 + len(v)
+	}
+	slices.Sort(tags)
+	out := make([]byte, 0, size)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(tags)))
+	off := 0
+	for i, t := range tags {
+		if i > 0 {
+			out = binary.LittleEndian.AppendUint32(out, uint32(off))
+		}
+		off += len(msg[t])
+	}
+	for _, t := range tags {
+		out = binary.LittleEndian.AppendUint32(out, uint32(t))
+	}
+	for _, t := range tags {
+		out = append(out, msg[t]...)
+	}
+	return out
+}
+
+// decode parses a message, validating its structure.
+func decode(b []byte) (map[tag][]byte, error) {
+	if len(b) < 4 || len(b)%4 != 0 {
+		return nil, errMalformed
+	}
+	n := int(binary.LittleEndian.Uint32(b))
+	if n == 0 {
+		return map[tag][]byte{}, nil
+	}
+	hdr := 4 + 4*(n-1) + 4*n
+	if n > len(b)/8 || hdr > len(b) {
+		return nil, errMalformed
+	}
+	body := b[hdr:]
+	msg := make(map[tag][]byte, n)
+	var prevTag tag
+	prevOff := 0
+	for i := range n {
+		t := tag(binary.LittleEndian.Uint32(b[4+4*(n-1)+4*i:]))
+		if i > 0 && t <= prevTag {
+			return nil, errMalformed
+		}
+		start := prevOff
+		end := len(body)
+		if i < n-1 {
+			end = int(binary.LittleEndian.Uint32(b[4+4*i:]))
+			if end%4 != 0 || end < start || end > len(body) {
+				return nil, errMalformed
+			}
+		}
+		msg[t] = body[start:end:end]
+		prevTag, prevOff = t, end
+	}
+	return msg, nil
+}
+
+// fixed returns the value of t, which must be exactly size bytes long.
+func fixed(msg map[tag][]byte, t tag, size int) ([]byte, error) {
+	v, ok := msg[t]
+	if !ok {
+		return nil, fmt.Errorf("roughtime: missing tag %v", t)
+	}
+	if len(v) != size {
+		return nil, fmt.Errorf("roughtime: tag %v has %d bytes, want %d", t, len(v), size)
+	}
+	return v, nil
+}
+
+func u32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func u64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+// leafHash and nodeHash build the Merkle tree over the nonces of a batch.
+func leafHash(nonce []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte{0})
+	h.Write(nonce)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// merkleRoot walks path from the leaf of nonce at index up to the root.
+func merkleRoot(nonce, path []byte, index uint32) ([]byte, error) {
+	if len(path)%hashSize != 0 {
+		return nil, errMalformed
+	}
+	h := leafHash(nonce)
+	for ; len(path) > 0; path = path[hashSize:] {
+		if index&1 == 0 {
+			h = nodeHash(h, path[:hashSize])
+		} else {
+			h = nodeHash(path[:hashSize], h)
+		}
+		index >>= 1
+	}
+	if index != 0 {
+		return nil, errors.New("roughtime: index exceeds Merkle path")
+	}
+	return h, nil
+}
@@ -0,0 +1,277 @@
+package roughtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trickstertwo/xclock/adapter/frozen"
+)
+
+var serverTime = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newServer(t *testing.T, radius time.Duration) *Server {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(ServerConfig{PrivateKey: priv, Clock: frozen.New(serverTime), Radius: radius})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func serve(t *testing.T, s *Server) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	go s.Serve(pc)
+	return pc.LocalAddr().String()
+}
+
+func nonce(t *testing.T) []byte {
+	t.Helper()
+	n := make([]byte, nonceSize)
+	if _, err := rand.Read(n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// respond returns a reply from s to a fresh request, and the request's nonce.
+func respond(t *testing.T, s *Server) (reply, n []byte) {
+	t.Helper()
+	n = nonce(t)
+	reply, err := s.Respond(newRequest(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reply, n
+}
+
+// rewrite decodes reply, lets f modify its top-level tags and re-encodes it.
+func rewrite(t *testing.T, reply []byte, f func(msg map[tag][]byte)) []byte {
+	t.Helper()
+	msg, err := decode(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f(msg)
+	return encode(msg)
+}
+
+func wantErr(t *testing.T, err error, substr string) {
+	t.Helper()
+	if err == nil || !strings.Contains(err.Error(), substr) {
+		t.Fatalf("error = %v, want it to contain %q", err, substr)
+	}
+}
+
+func TestRespondVerifies(t *testing.T) {
+	s := newServer(t, 10*time.Millisecond)
+	reply, n := respond(t, s)
+	midp, radius, err := verify(s.PublicKey(), n, reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !midp.Equal(serverTime) {
+		t.Fatalf("midpoint = %v, want %v", midp, serverTime)
+	}
+	if radius != 10*time.Millisecond {
+		t.Fatalf("radius = %v, want 10ms", radius)
+	}
+}
+
+func TestQueryOverUDP(t *testing.T) {
+	s := newServer(t, 10*time.Millisecond)
+	c := New(Config{Address: serve(t, s), PublicKey: s.PublicKey()})
+	r, err := c.Query(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Midpoint.Equal(serverTime) || r.Radius != 10*time.Millisecond {
+		t.Fatalf("result = %+v", r)
+	}
+	if r.Uncertainty() != r.Radius+r.RTT/2 {
+		t.Fatalf("uncertainty = %v, want radius + RTT/2", r.Uncertainty())
+	}
+	got, err := c.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Sub(serverTime); d < 0 || d > time.Second {
+		t.Fatalf("Fetch = %v, want just after %v", got, serverTime)
+	}
+}
+
+func TestServeDropsShortRequests(t *testing.T) {
+	s := newServer(t, 0)
+	if _, err := s.Respond(make([]byte, 64)); err == nil {
+		t.Fatal("short request answered")
+	}
+	conn, err := net.Dial("udp", serve(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write(make([]byte, 64))
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 4096)); err == nil {
+		t.Fatal("short request answered over UDP")
+	}
+}
+
+func TestBadDelegationSignature(t *testing.T) {
+	s := newServer(t, 0)
+	reply, n := respond(t, s)
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	_, _, err := verify(other, n, reply)
+	wantErr(t, err, "bad delegation signature")
+
+	// Over the network too: no reply verifies, so the query times out.
+	c := New(Config{Address: serve(t, s), PublicKey: other, Timeout: 100 * time.Millisecond, Attempts: 1})
+	_, err = c.Query(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("error = %v, want ErrTimeout", err)
+	}
+	wantErr(t, err, "bad delegation signature")
+}
+
+func TestBadResponseSignature(t *testing.T) {
+	s := newServer(t, 0)
+	reply, n := respond(t, s)
+	reply = rewrite(t, reply, func(msg map[tag][]byte) {
+		srep, err := decode(msg[tagSREP])
+		if err != nil {
+			t.Fatal(err)
+		}
+		srep[tagMIDP] = u64(uint64(serverTime.Add(time.Hour).UnixMicro()))
+		msg[tagSREP] = encode(srep)
+	})
+	_, _, err := verify(s.PublicKey(), n, reply)
+	wantErr(t, err, "bad response signature")
+}
+
+func TestWrongNonce(t *testing.T) {
+	s := newServer(t, 0)
+	reply, _ := respond(t, s)
+	_, _, err := verify(s.PublicKey(), nonce(t), reply)
+	wantErr(t, err, "nonce not in signed Merkle root")
+}
+
+func TestBadMerklePath(t *testing.T) {
+	s := newServer(t, 0)
+	reply, n := respond(t, s)
+
+	withPath := rewrite(t, reply, func(msg map[tag][]byte) { msg[tagPATH] = bytes.Repeat([]byte{7}, hashSize) })
+	_, _, err := verify(s.PublicKey(), n, withPath)
+	wantErr(t, err, "nonce not in signed Merkle root")
+
+	badIndex := rewrite(t, reply, func(msg map[tag][]byte) { msg[tagINDX] = u32(1) })
+	_, _, err = verify(s.PublicKey(), n, badIndex)
+	wantErr(t, err, "index exceeds Merkle path")
+
+	ragged := rewrite(t, reply, func(msg map[tag][]byte) { msg[tagPATH] = make([]byte, 8) })
+	_, _, err = verify(s.PublicKey(), n, ragged)
+	if !errors.Is(err, errMalformed) {
+		t.Fatalf("error = %v, want errMalformed", err)
+	}
+}
+
+func TestMerklePath(t *testing.T) {
+	// Four leaves; prove the last one.
+	var n [4][]byte
+	var l [4][]byte
+	for i := range n {
+		n[i] = bytes.Repeat([]byte{byte(i)}, nonceSize)
+		l[i] = leafHash(n[i])
+	}
+	left, right := nodeHash(l[0], l[1]), nodeHash(l[2], l[3])
+	root := nodeHash(left, right)
+	path := append(append([]byte(nil), l[2]...), left...)
+	got, err := merkleRoot(n[3], path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, root) {
+		t.Fatal("root mismatch for a valid path")
+	}
+	if got, _ := merkleRoot(n[3], path, 1); bytes.Equal(got, root) {
+		t.Fatal("root matches with the wrong index")
+	}
+}
+
+// signedReply builds a reply to nonce n whose delegation is valid over
+// [mint, maxt], signed by root.
+func signedReply(t *testing.T, root ed25519.PrivateKey, n []byte, midp, mint, maxt time.Time) []byte {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dele := encode(map[tag][]byte{
+		tagPUBK: pub,
+		tagMINT: u64(uint64(mint.UnixMicro())),
+		tagMAXT: u64(uint64(maxt.UnixMicro())),
+	})
+	cert := encode(map[tag][]byte{
+		tagSIG:  ed25519.Sign(root, append(append([]byte(nil), delegationContext...), dele...)),
+		tagDELE: dele,
+	})
+	srep := encode(map[tag][]byte{
+		tagRADI: u32(1000),
+		tagMIDP: u64(uint64(midp.UnixMicro())),
+		tagROOT: leafHash(n),
+	})
+	return encode(map[tag][]byte{
+		tagSIG:  ed25519.Sign(key, append(append([]byte(nil), responseContext...), srep...)),
+		tagPATH: {},
+		tagSREP: srep,
+		tagCERT: cert,
+		tagINDX: u32(0),
+	})
+}
+
+func TestDelegationValidity(t *testing.T) {
+	pub, root, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := nonce(t)
+	mint, maxt := serverTime.Add(-time.Hour), serverTime.Add(time.Hour)
+
+	if _, _, err := verify(pub, n, signedReply(t, root, n, serverTime, mint, maxt)); err != nil {
+		t.Fatalf("valid delegation: %v", err)
+	}
+	_, _, err = verify(pub, n, signedReply(t, root, n, maxt.Add(time.Second), mint, maxt))
+	wantErr(t, err, "outside delegation validity")
+	_, _, err = verify(pub, n, signedReply(t, root, n, mint.Add(-time.Second), mint, maxt))
+	wantErr(t, err, "outside delegation validity")
+}
+
+func TestMaxUncertainty(t *testing.T) {
+	s := newServer(t, time.Second)
+	addr := serve(t, s)
+	c := New(Config{Address: addr, PublicKey: s.PublicKey(), MaxUncertainty: 10 * time.Millisecond, Attempts: 2})
+	_, err := c.Query(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("error = %v, want ErrTimeout", err)
+	}
+	wantErr(t, err, "uncertainty")
+
+	c = New(Config{Address: addr, PublicKey: s.PublicKey(), MaxUncertainty: 2 * time.Second})
+	if _, err := c.Query(context.Background()); err != nil {
+		t.Fatalf("within MaxUncertainty: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package roughtime
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+type ServerConfig struct {
+	// PrivateKey is the long-term Ed25519 key. It only signs delegations.
+	PrivateKey ed25519.PrivateKey
+	// Clock is the time served. If nil, xclock.Default() is used at each request.
+	Clock xclock.Clock
+	// Radius is the uncertainty announced with each reply. Defaults to 1s.
+	Radius time.Duration
+	// Delegation is how long each online key is valid. Defaults to 24h; a new one
+	// is created when half of it has passed.
+	Delegation time.Duration
+}
+
+// Server is a minimal Roughtime responder: it answers each request on its own
+// (a one-leaf Merkle tree) with an online key delegated by PrivateKey.
+type Server struct {
+	root     ed25519.PrivateKey
+	clk      xclock.Clock
+	radius   time.Duration
+	validity time.Duration
+
+	mu      sync.Mutex
+	key     ed25519.PrivateKey // online key
+	cert    []byte             // CERT value for key
+	mint    time.Time          // start of key's validity
+	renewAt time.Time
+}
+
+// NewServer constructs a responder.
+func NewServer(cfg ServerConfig) (*Server, error) {
+	if len(cfg.PrivateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("roughtime: invalid private key")
+	}
+	if cfg.Radius <= 0 {
+		cfg.Radius = time.Second
+	}
+	if cfg.Delegation <= 0 {
+		cfg.Delegation = 24 * time.Hour
+	}
+	return &Server{root: cfg.PrivateKey, clk: cfg.Clock, radius: cfg.Radius, validity: cfg.Delegation}, nil
+}
+
+// PublicKey returns the long-term public key clients verify against.
+func (s *Server) PublicKey() ed25519.PublicKey { return s.root.Public().(ed25519.PublicKey) }
+
+func (s *Server) now() time.Time {
+	if s.clk == nil {
+		return xclock.Default().Now()
+	}
+	return s.clk.Now()
+}
+
+// delegation returns the online key and its certificate, renewing them as needed.
+func (s *Server) delegation(now time.Time) (ed25519.PrivateKey, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key != nil && now.Before(s.renewAt) && !now.Before(s.mint) {
+		return s.key, s.cert, nil
+	}
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Valid from half a period back so clients slightly behind still accept it.
+	mint := now.Add(-s.validity / 2)
+	dele := encode(map[tag][]byte{
+		tagPUBK: pub,
+		tagMINT: u64(uint64(max(mint.UnixMicro(), 0))),
+		tagMAXT: u64(uint64(max(now.Add(s.validity).UnixMicro(), 0))),
+	})
+	sig := ed25519.Sign(s.root, append(append([]byte(nil), delegationContext...), dele...))
+	s.key = key
+	s.cert = encode(map[tag][]byte{tagSIG: sig, tagDELE: dele})
+	s.mint = mint
+	s.renewAt = now.Add(s.validity / 2)
+	return s.key, s.cert, nil
+}
+
+// Respond returns the reply to one request datagram.
+func (s *Server) Respond(request []byte) ([]byte, error) {
+	if len(request) < requestSize {
+		return nil, errors.New("roughtime: request too short")
+	}
+	req, err := decode(request)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := fixed(req, tagNONC, nonceSize)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	key, cert, err := s.delegation(now)
+	if err != nil {
+		return nil, err
+	}
+	srep := encode(map[tag][]byte{
+		tagRADI: u32(uint32(min(s.radius.Microseconds(), 1<<32-1))),
+		tagMIDP: u64(uint64(max(now.UnixMicro(), 0))),
+		tagROOT: leafHash(nonce),
+	})
+	sig := ed25519.Sign(key, append(append([]byte(nil), responseContext...), srep...))
+	return encode(map[tag][]byte{
+		tagSIG:  sig,
+		tagPATH: {},
+		tagSREP: srep,
+		tagCERT: cert,
+		tagINDX: u32(0),
+	}), nil
+}
+
+// Serve answers requests on conn until it is closed, returning the read error.
+// Malformed requests are dropped.
+func (s *Server) Serve(conn net.PacketConn) error {
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		reply, err := s.Respond(buf[:n])
+		if err != nil {
+			continue
+		}
+		_, _ = conn.WriteTo(reply, addr)
+	}
+}
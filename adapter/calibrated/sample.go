@@ -0,0 +1,191 @@
+package calibrated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sampling: a sync takes Samples fetches, times each on the base clock and keeps
+// the one with the smallest round trip, whose offset is least disturbed by
+// network delay (the NTP clock-filter idea). A step larger than MaxStep from the
+// current offset is only applied when enough samples agree with it, or when
+// enough consecutive syncs measure it (NTP's stepout).
+//
+// Notes:
+// - By default a fetched time is taken to be the authority's time when fetch
+//   returns, which suits fetchers that already correct for latency (httptime,
+//   roughtime). Set Midpoint for fetchers returning a raw server timestamp; it
+//   is then placed halfway through the round trip.
+// - The first successful sync is never step-limited.
+// - A rejected step leaves the offset unchanged and counts as a failed sync
+//   (ErrStepRejected), so auto-sync retries it with backoff. A step measured by
+//   Confirm consecutive syncs is accepted even if no single sync confirms it, so
+//   a real clock step (e.g. the OS clock was set) is always taken eventually,
+//   also with fewer Samples than Confirm.
+
+// Sampling configures how SyncOnce and Sync take and filter samples.
+type Sampling struct {
+	// Samples is the number of fetches per sync. Defaults to 1.
+	Samples int
+	// Midpoint places each fetched time at the middle of its round trip.
+	Midpoint bool
+	// MaxStep rejects a new offset further than this from the current one unless
+	// it is confirmed. 0 disables the limit.
+	MaxStep time.Duration
+	// Confirm is how many samples of one sync, the best included, or how many
+	// consecutive syncs must agree to accept a step beyond MaxStep. Defaults to 2.
+	Confirm int
+	// Tolerance is how close a sample's offset must be to the best one to agree
+	// with it. Defaults to the best sample's round trip, at least 1ms.
+	Tolerance time.Duration
+}
+
+// SyncResult describes one sync.
+type SyncResult struct {
+	// Offset is the best sample's offset; it was applied unless the sync failed.
+	Offset time.Duration
+	// Previous is the offset before the sync, as predicted at the best sample.
+	Previous time.Duration
+	// RTT is the best sample's round trip.
+	RTT time.Duration
+	// Samples and Failed count successful and failed fetches.
+	Samples int
+	Failed  int
+	// Agreeing counts samples, the best included, whose offset agrees with it.
+	Agreeing int
+	// Consecutive counts syncs in a row, this one included, that measured a step
+	// beyond MaxStep to about this offset; 0 when the sync was within MaxStep.
+	Consecutive int
+	// Applied reports whether Offset was set on the clock.
+	Applied bool
+}
+
+// Step returns the change the sync made, or would have made, to the offset.
+func (r SyncResult) Step() time.Duration { return r.Offset - r.Previous }
+
+// ErrStepRejected is returned (wrapped) when a step beyond Sampling.MaxStep was
+// not confirmed by enough samples.
+var ErrStepRejected = errors.New("calibrated: step rejected")
+
+// SetSampling sets how subsequent syncs sample; see Sampling.
+func (c *Clock) SetSampling(s Sampling) {
+	if s.Samples <= 0 {
+		s.Samples = 1
+	}
+	if s.Confirm <= 0 {
+		s.Confirm = 2
+	}
+	s.MaxStep = max(s.MaxStep, 0)
+	c.sampling.Store(&s)
+}
+
+// Sampling returns the current sampling configuration.
+func (c *Clock) Sampling() Sampling {
+	if s := c.sampling.Load(); s != nil {
+		return *s
+	}
+	return Sampling{Samples: 1, Confirm: 2}
+}
+
+// stepout tracks a step beyond MaxStep seen by consecutive syncs.
+type stepout struct {
+	mu     sync.Mutex
+	offset time.Duration
+	count  int
+}
+
+// see records a step to offset and returns how many consecutive syncs saw it.
+func (s *stepout) see(offset, tol time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count > 0 && (offset-s.offset).Abs() <= tol {
+		s.count++
+	} else {
+		s.count = 1
+	}
+	s.offset = offset
+	return s.count
+}
+
+func (s *stepout) reset() {
+	s.mu.Lock()
+	s.count = 0
+	s.mu.Unlock()
+}
+
+type sample struct {
+	offset time.Duration
+	rtt    time.Duration
+	at     time.Time // base time the offset was measured at
+}
+
+// Sync is SyncOnce returning a SyncResult. Failed fetches are skipped; if all
+// fail, the last error is returned.
+func (c *Clock) Sync(ctx context.Context, fetch func(context.Context) (time.Time, error)) (SyncResult, error) {
+	cfg := c.Sampling()
+	var res SyncResult
+	samples := make([]sample, 0, cfg.Samples)
+	var lastErr error
+	for range cfg.Samples {
+		start := c.base.Now()
+		t, err := fetch(ctx)
+		end := c.base.Now()
+		if err != nil {
+			res.Failed++
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		rtt := end.Sub(start)
+		at := end
+		if cfg.Midpoint {
+			at = start.Add(rtt / 2)
+		}
+		samples = append(samples, sample{offset: t.Sub(at), rtt: rtt, at: at})
+	}
+	res.Samples = len(samples)
+	if len(samples) == 0 {
+		c.recordFailure(lastErr)
+		return res, lastErr
+	}
+
+	best := samples[0]
+	for _, s := range samples[1:] {
+		if s.rtt < best.rtt {
+			best = s
+		}
+	}
+	res.Offset, res.RTT = best.offset, best.rtt
+	res.Previous = c.offsetAt(best.at)
+	tol := cfg.Tolerance
+	if tol <= 0 {
+		tol = max(best.rtt, time.Millisecond)
+	}
+	for _, s := range samples {
+		if (s.offset - best.offset).Abs() <= tol {
+			res.Agreeing++
+		}
+	}
+
+	if cfg.MaxStep > 0 && res.Step().Abs() > cfg.MaxStep && c.Status().Syncs > 0 {
+		// Syncs agree on a step when their offsets are within MaxStep of each
+		// other: relative to one another they did not step.
+		res.Consecutive = c.stepout.see(best.offset, max(tol, cfg.MaxStep))
+		if res.Agreeing < cfg.Confirm && res.Consecutive < cfg.Confirm {
+			err := fmt.Errorf("%w: %v exceeds max step %v with %d samples and %d syncs agreeing of %d",
+				ErrStepRejected, res.Step(), cfg.MaxStep, res.Agreeing, res.Consecutive, cfg.Confirm)
+			c.recordFailure(err)
+			return res, err
+		}
+	}
+	c.stepout.reset()
+	c.observe(best.offset, best.at)
+	res.Applied = true
+	c.recordSuccess(best.offset, best.rtt)
+	return res, nil
+}
@@ -0,0 +1,208 @@
+package calibrated
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/backoff"
+)
+
+// Sync health: every SyncOnce (direct or from the auto-sync loop) updates a
+// Status, so callers can tell a clock that has not been calibrated for hours
+// from a healthy one.
+//
+// Notes:
+// - Staleness is measured on the base clock since the last successful sync (or
+//   since construction before the first one); past StaleAfter the clock is
+//   Degraded. Now() keeps applying the last offset either way.
+// - OnSync and OnError run synchronously after the Status is updated, outside
+//   internal locks; keep them short.
+// - The auto-sync loop syncs immediately, then every Period; after a failure it
+//   retries on the Backoff strategy (capped at Period) until a sync succeeds.
+
+// Status describes the calibration state.
+type Status struct {
+	// LastSuccess is the calibrated time of the last successful sync; zero if none.
+	LastSuccess time.Time
+	// LastAttempt is the calibrated time of the last sync attempt; zero if none.
+	LastAttempt time.Time
+	// LastError is the error of the last failed sync; it is kept after later
+	// successes, see LastErrorAt.
+	LastError   error
+	LastErrorAt time.Time
+	// ConsecutiveFailures counts failed syncs since the last success.
+	ConsecutiveFailures int
+	// Syncs and Failures count all successful and failed syncs.
+	Syncs    uint64
+	Failures uint64
+	// Offset is the offset set by the last successful sync.
+	Offset time.Duration
+	// RTT is the round trip of the sample used by the last successful sync.
+	RTT time.Duration
+	// PPM is the estimated frequency error applied by Now(); see Drift.
+	PPM float64
+	// Degraded reports that no sync has succeeded within the staleness threshold.
+	Degraded bool
+}
+
+// health is the mutable sync state behind Status.
+type health struct {
+	mu         sync.Mutex
+	st         Status
+	ref        time.Time // base time of the last success, or of construction
+	staleAfter time.Duration
+	onSync     func(Status)
+	onError    func(error, Status)
+}
+
+// SetStaleAfter sets the staleness threshold after which Status reports the
+// clock as Degraded. 0 disables it.
+func (c *Clock) SetStaleAfter(d time.Duration) {
+	c.health.mu.Lock()
+	c.health.staleAfter = max(d, 0)
+	c.health.mu.Unlock()
+}
+
+// OnSync sets a callback run after every successful sync. nil removes it.
+func (c *Clock) OnSync(f func(Status)) {
+	c.health.mu.Lock()
+	c.health.onSync = f
+	c.health.mu.Unlock()
+}
+
+// OnError sets a callback run after every failed sync. nil removes it.
+func (c *Clock) OnError(f func(error, Status)) {
+	c.health.mu.Lock()
+	c.health.onError = f
+	c.health.mu.Unlock()
+}
+
+// Status returns the current calibration state.
+func (c *Clock) Status() Status {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+	return c.statusLocked()
+}
+
+// Degraded reports whether no sync has succeeded within the staleness threshold.
+func (c *Clock) Degraded() bool { return c.Status().Degraded }
+
+func (c *Clock) statusLocked() Status {
+	st := c.health.st
+	st.PPM = c.PPM()
+	st.Degraded = c.health.staleAfter > 0 && c.base.Since(c.health.ref) > c.health.staleAfter
+	return st
+}
+
+func (c *Clock) recordSuccess(offset, rtt time.Duration) {
+	c.health.mu.Lock()
+	now := c.Now()
+	c.health.ref = c.base.Now()
+	h := &c.health.st
+	h.LastSuccess, h.LastAttempt = now, now
+	h.ConsecutiveFailures = 0
+	h.Syncs++
+	h.Offset, h.RTT = offset, rtt
+	st, f := c.statusLocked(), c.health.onSync
+	c.health.mu.Unlock()
+	if f != nil {
+		f(st)
+	}
+}
+
+func (c *Clock) recordFailure(err error) {
+	c.health.mu.Lock()
+	now := c.Now()
+	h := &c.health.st
+	h.LastAttempt = now
+	h.LastError, h.LastErrorAt = err, now
+	h.ConsecutiveFailures++
+	h.Failures++
+	st, f := c.statusLocked(), c.health.onError
+	c.health.mu.Unlock()
+	if f != nil {
+		f(err, st)
+	}
+}
+
+// AutoSyncConfig configures AutoSync.
+type AutoSyncConfig struct {
+	// Period is the interval between successful syncs. Required.
+	Period time.Duration
+	// Fetch returns authoritative time, as for SyncOnce. Required.
+	Fetch func(context.Context) (time.Time, error)
+	// Scheduler times the loop. If nil, the base clock is used.
+	Scheduler xclock.Clock
+	// Backoff spaces retries after failures; delays are capped at Period.
+	// Defaults to exponential from Period/32 with ±20% jitter.
+	Backoff backoff.Strategy
+	// Wait makes AutoSync perform the first sync before returning and return its
+	// error. The loop runs either way.
+	Wait bool
+}
+
+// AutoSync starts a calibration loop that syncs immediately and then every
+// Period, retrying failures with backoff. It returns an idempotent cancel
+// function; the loop also stops when ctx is done. With cfg.Wait the error is
+// that of the first sync, otherwise it only reports an invalid configuration.
+func (c *Clock) AutoSync(ctx context.Context, cfg AutoSyncConfig) (cancel func(), err error) {
+	if cfg.Period <= 0 || cfg.Fetch == nil {
+		return func() {}, errors.New("calibrated: AutoSync needs a positive Period and a Fetch")
+	}
+	if cfg.Scheduler == nil {
+		cfg.Scheduler = c.base
+	}
+	if cfg.Backoff == nil {
+		cfg.Backoff = backoff.NewExponential(backoff.ExponentialConfig{
+			Initial: max(cfg.Period/32, time.Millisecond),
+			Max:     cfg.Period,
+			Jitter:  0.2,
+		})
+	}
+
+	var first error
+	synced := false
+	if cfg.Wait {
+		first = c.SyncOnce(ctx, cfg.Fetch)
+		synced = true
+	}
+
+	stop := make(chan struct{})
+	var once sync.Once
+	go c.autoSync(ctx, cfg, stop, synced, first)
+	return func() { once.Do(func() { close(stop) }) }, first
+}
+
+// autoSync runs the loop; if synced, the first sync already ran with result err.
+func (c *Clock) autoSync(ctx context.Context, cfg AutoSyncConfig, stop <-chan struct{}, synced bool, err error) {
+	if !synced {
+		err = c.SyncOnce(ctx, cfg.Fetch)
+	}
+	failures := 0
+	var delay time.Duration
+	next := func() time.Duration {
+		if err == nil {
+			failures, delay = 0, 0
+			return cfg.Period
+		}
+		failures++
+		delay = min(cfg.Backoff.Delay(failures, delay), cfg.Period)
+		return delay
+	}
+	tm := cfg.Scheduler.NewTimer(next())
+	defer tm.Stop()
+	for {
+		select {
+		case <-tm.C():
+			err = c.SyncOnce(ctx, cfg.Fetch)
+			tm.Reset(next())
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
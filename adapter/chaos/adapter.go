@@ -0,0 +1,397 @@
+package chaos
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/internal/splitmix"
+)
+
+// Chaos clock: seeded, probability-based fault injection for scheduling. Where
+// adapter/jitter perturbs Now(), chaos makes sleeps overshoot, timers and
+// AfterFuncs fire late or early, and tickers drop, delay or duplicate ticks.
+// For resilience tests of retry, lease and heartbeat logic.
+//
+// Notes:
+// - Rules are evaluated in order for each call (each tick, for tickers); the
+//   first rule that matches the method and wins its roll applies.
+// - Faults and where they apply:
+//   - Latency: Now() stalls before reading; Sleep, After, timers and AfterFunc
+//     wait longer; ticks are delivered late.
+//   - Early: Sleep, After, timers and AfterFunc wait less (never below zero).
+//   - Drop: a tick is not delivered.
+//   - Duplicate: a tick is delivered twice.
+//   A rule whose fault does not apply to the method is ignored.
+// - Timer durations are perturbed when armed (NewTimer, Reset).
+// - Rules can be replaced at runtime with SetRules; every injected fault is
+//   recorded (see Injections).
+// - Draws are deterministic for a given Seed; which call receives which draw
+//   still depends on scheduling.
+
+// Method selects the Clock methods a Rule targets. Combine with |.
+type Method uint8
+
+const (
+	MethodNow Method = 1 << iota
+	MethodSleep
+	MethodAfter
+	MethodAfterFunc
+	MethodTimer
+	MethodTicker
+
+	MethodAll = MethodNow | MethodSleep | MethodAfter | MethodAfterFunc | MethodTimer | MethodTicker
+)
+
+func (m Method) String() string {
+	switch m {
+	case MethodNow:
+		return "Now"
+	case MethodSleep:
+		return "Sleep"
+	case MethodAfter:
+		return "After"
+	case MethodAfterFunc:
+		return "AfterFunc"
+	case MethodTimer:
+		return "Timer"
+	case MethodTicker:
+		return "Ticker"
+	}
+	return "Method(" + strconv.Itoa(int(m)) + ")"
+}
+
+// Fault is the kind of misbehavior a Rule injects.
+type Fault uint8
+
+const (
+	Latency Fault = iota
+	Early
+	Drop
+	Duplicate
+)
+
+func (f Fault) String() string {
+	switch f {
+	case Latency:
+		return "Latency"
+	case Early:
+		return "Early"
+	case Drop:
+		return "Drop"
+	case Duplicate:
+		return "Duplicate"
+	}
+	return "Fault(" + strconv.Itoa(int(f)) + ")"
+}
+
+// applies reports whether f is meaningful for m.
+func (f Fault) applies(m Method) bool {
+	switch f {
+	case Latency:
+		return true
+	case Early:
+		return m&(MethodSleep|MethodAfter|MethodAfterFunc|MethodTimer) != 0
+	case Drop, Duplicate:
+		return m == MethodTicker
+	}
+	return false
+}
+
+// Rule injects Fault into calls of Methods with the given Probability.
+type Rule struct {
+	Methods     Method
+	Fault       Fault
+	Probability float64 // in [0, 1]
+	// Amount sizes Latency and Early faults. If nil, Fixed(10ms) is used.
+	Amount Distribution
+}
+
+// Injection records one injected fault.
+type Injection struct {
+	At     time.Time // base time of the call
+	Method Method
+	Fault  Fault
+	Amount time.Duration // Latency/Early only
+}
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Rules are the initial fault rules.
+	Rules []Rule
+	// Seed initializes the PRNG. If 0, it's seeded from time.Now().UnixNano().
+	Seed uint64
+	// MaxRecords bounds the retained injection log (oldest dropped first).
+	// Defaults to 1024; counts in Stats are not bounded.
+	MaxRecords int
+}
+
+// Set sets the chaos clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := chaos.Set(chaos.Config{Seed: 1, Rules: []chaos.Rule{
+//		{Methods: chaos.MethodTicker, Fault: chaos.Drop, Probability: 0.1},
+//		{Methods: chaos.MethodTimer | chaos.MethodAfterFunc, Fault: chaos.Latency, Probability: 0.2,
+//			Amount: chaos.Uniform(10*time.Millisecond, 200*time.Millisecond)},
+//	}})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the chaos clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the chaos clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+type Clock struct {
+	base    xclock.Clock
+	rng     *splitmix.Rand
+	rules   atomic.Pointer[[]Rule]
+	enabled atomic.Bool
+
+	mu      sync.Mutex
+	log     []Injection
+	max     int
+	dropped uint64
+	counts  map[Fault]uint64
+}
+
+// New constructs a chaos clock. It starts enabled.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = uint64(time.Now().UnixNano())
+	}
+	if cfg.MaxRecords <= 0 {
+		cfg.MaxRecords = 1024
+	}
+	c := &Clock{
+		base:   cfg.Base,
+		rng:    splitmix.New(cfg.Seed),
+		max:    cfg.MaxRecords,
+		counts: make(map[Fault]uint64),
+	}
+	c.SetRules(cfg.Rules)
+	c.enabled.Store(true)
+	return c
+}
+
+// SetRules replaces the rule set; it applies to subsequent calls and ticks.
+func (c *Clock) SetRules(rules []Rule) {
+	r := append([]Rule(nil), rules...)
+	c.rules.Store(&r)
+}
+
+// Rules returns a copy of the current rule set.
+func (c *Clock) Rules() []Rule { return append([]Rule(nil), *c.rules.Load()...) }
+
+// SetEnabled switches fault injection on or off without touching the rules.
+func (c *Clock) SetEnabled(on bool) { c.enabled.Store(on) }
+
+// Enabled reports whether faults are being injected.
+func (c *Clock) Enabled() bool { return c.enabled.Load() }
+
+// Injections returns a copy of the retained injection log, oldest first.
+func (c *Clock) Injections() []Injection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Injection(nil), c.log...)
+}
+
+// Stats summarizes injected faults since construction or the last ResetStats.
+type Stats struct {
+	Total     uint64
+	Latency   uint64
+	Early     uint64
+	Drop      uint64
+	Duplicate uint64
+	// Discarded is the number of injections no longer in the log (MaxRecords).
+	Discarded uint64
+}
+
+// Stats returns fault counts.
+func (c *Clock) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := Stats{
+		Latency:   c.counts[Latency],
+		Early:     c.counts[Early],
+		Drop:      c.counts[Drop],
+		Duplicate: c.counts[Duplicate],
+		Discarded: c.dropped,
+	}
+	s.Total = s.Latency + s.Early + s.Drop + s.Duplicate
+	return s
+}
+
+// ResetStats clears the injection log and counts.
+func (c *Clock) ResetStats() {
+	c.mu.Lock()
+	c.log = nil
+	c.dropped = 0
+	clear(c.counts)
+	c.mu.Unlock()
+}
+
+// roll picks the fault, if any, for one call of m and records it.
+func (c *Clock) roll(m Method) (Fault, time.Duration, bool) {
+	if !c.enabled.Load() {
+		return 0, 0, false
+	}
+	for _, r := range *c.rules.Load() {
+		if r.Methods&m == 0 || !r.Fault.applies(m) || r.Probability <= 0 {
+			continue
+		}
+		if r.Probability < 1 && c.rng.Float64() >= r.Probability {
+			continue
+		}
+		var amt time.Duration
+		if r.Fault == Latency || r.Fault == Early {
+			dist := r.Amount
+			if dist == nil {
+				dist = Fixed(10 * time.Millisecond)
+			}
+			amt = max(dist.Sample(c.rng), 0)
+		}
+		c.record(Injection{At: c.base.Now(), Method: m, Fault: r.Fault, Amount: amt})
+		return r.Fault, amt, true
+	}
+	return 0, 0, false
+}
+
+func (c *Clock) record(in Injection) {
+	c.mu.Lock()
+	c.counts[in.Fault]++
+	if len(c.log) >= c.max {
+		n := copy(c.log, c.log[1:])
+		c.log = c.log[:n]
+		c.dropped++
+	}
+	c.log = append(c.log, in)
+	c.mu.Unlock()
+}
+
+// perturb applies a Latency or Early fault to a one-shot duration.
+func (c *Clock) perturb(m Method, d time.Duration) time.Duration {
+	f, amt, ok := c.roll(m)
+	if !ok {
+		return d
+	}
+	if f == Early {
+		return max(d-amt, 0)
+	}
+	return d + amt
+}
+
+// Now returns base time, possibly after an injected stall.
+func (c *Clock) Now() time.Time {
+	if _, amt, ok := c.roll(MethodNow); ok {
+		c.base.Sleep(amt)
+	}
+	return c.base.Now()
+}
+
+func (c *Clock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration)           { c.base.Sleep(c.perturb(MethodSleep, d)) }
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.base.After(c.perturb(MethodAfter, d))
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(c.perturb(MethodAfterFunc, d), f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	return &timer{c: c, t: c.base.NewTimer(c.perturb(MethodTimer, d))}
+}
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	t := &ticker{
+		c:    c,
+		t:    c.base.NewTicker(d),
+		ch:   make(chan time.Time, 1),
+		done: make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+type timer struct {
+	c *Clock
+	t xclock.Timer
+}
+
+func (t *timer) C() <-chan time.Time        { return t.t.C() }
+func (t *timer) Stop() bool                 { return t.t.Stop() }
+func (t *timer) Reset(d time.Duration) bool { return t.t.Reset(t.c.perturb(MethodTimer, d)) }
+
+// ticker relays base ticks through a goroutine that applies tick faults.
+type ticker struct {
+	c    *Clock
+	t    xclock.Ticker
+	ch   chan time.Time
+	done chan struct{}
+	once sync.Once
+}
+
+func (t *ticker) C() <-chan time.Time   { return t.ch }
+func (t *ticker) Reset(d time.Duration) { t.t.Reset(d) }
+func (t *ticker) Stop() {
+	t.t.Stop()
+	t.once.Do(func() { close(t.done) })
+}
+
+func (t *ticker) run() {
+	for {
+		var at time.Time
+		select {
+		case at = <-t.t.C():
+		case <-t.done:
+			return
+		}
+		f, amt, ok := t.c.roll(MethodTicker)
+		switch {
+		case !ok:
+		case f == Drop:
+			continue
+		case f == Latency:
+			select {
+			case <-t.c.base.After(amt):
+			case <-t.done:
+				return
+			}
+		case f == Duplicate:
+			// The first copy is dropped like any tick for a slow receiver;
+			// the second waits until it is received.
+			select {
+			case t.ch <- at:
+			default:
+			}
+			select {
+			case t.ch <- at:
+			case <-t.done:
+				return
+			}
+			continue
+		}
+		select {
+		case t.ch <- at:
+		default:
+		}
+	}
+}
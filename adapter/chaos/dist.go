@@ -0,0 +1,50 @@
+package chaos
+
+import (
+	"math"
+	"time"
+)
+
+// Rand is the random source a Distribution draws from. The clock passes its
+// seeded generator; *math/rand/v2.Rand also satisfies it.
+type Rand interface {
+	Float64() float64
+	Uint64() uint64
+}
+
+// Distribution draws the size of a Latency or Early fault.
+type Distribution interface {
+	Sample(rng Rand) time.Duration
+}
+
+// DistributionFunc adapts a function to Distribution.
+type DistributionFunc func(rng Rand) time.Duration
+
+func (f DistributionFunc) Sample(rng Rand) time.Duration { return f(rng) }
+
+// Fixed always returns d.
+func Fixed(d time.Duration) Distribution {
+	return DistributionFunc(func(Rand) time.Duration { return d })
+}
+
+// Uniform draws uniformly from [lo, hi].
+func Uniform(lo, hi time.Duration) Distribution {
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	return DistributionFunc(func(rng Rand) time.Duration {
+		return lo + time.Duration(rng.Float64()*float64(hi-lo))
+	})
+}
+
+// Exponential draws from an exponential distribution with the given mean,
+// capped at limit (no cap if limit <= 0). Models long-tail scheduling delays.
+func Exponential(mean, limit time.Duration) Distribution {
+	return DistributionFunc(func(rng Rand) time.Duration {
+		d := time.Duration(-math.Log(1-rng.Float64()) * float64(mean))
+		if limit > 0 && d > limit {
+			d = limit
+		}
+		return d
+	})
+}
@@ -0,0 +1,256 @@
+package coarse
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Coarse clock: Now() returns a cached time refreshed by a background updater
+// every Resolution on the base clock. For hot paths (logging, metrics) that call
+// Now() millions of times per second and only need millisecond precision.
+//
+// Notes:
+// - Without BoundStaleness, Now() is a single atomic load; no call into the
+//   base clock.
+// - The updater runs only between Start and Stop. When stopped, Now() reads the
+//   base clock directly.
+// - While running, a value is Resolution old plus updater scheduling delay, which
+//   is unbounded if the updater is starved. Refreshes later than MaxStale are
+//   counted (Stats().Overruns). With BoundStaleness, Now() also checks the
+//   cached value's age on the base clock and, past MaxStale, reads the base
+//   directly and refreshes the cache (Stats().Fallbacks): staleness is then
+//   bounded by MaxStale, at the cost of one base read per call.
+// - Now() never goes backwards, even if the base does, also across Stop and
+//   Start: while the base is behind the last cached value, Now() holds it.
+// - Sleep/After/Timers/Tickers delegate to the base clock unchanged.
+
+type Config struct {
+	// Base is the underlying clock to cache. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Resolution is the refresh interval. Defaults to 1ms.
+	Resolution time.Duration
+	// MaxStale is the refresh gap counted as an overrun, and the staleness bound
+	// under BoundStaleness. Defaults to 2×Resolution.
+	MaxStale time.Duration
+	// BoundStaleness makes Now() fall back to the base clock when the cached
+	// value is older than MaxStale. Without it Now() never reads the base.
+	BoundStaleness bool
+}
+
+// Set sets a started coarse clock as the process-wide default and returns a restore
+// function that reverts to the previous default and stops the updater.
+// Recommended for tests and examples:
+//
+//	restore := coarse.Set(coarse.Config{Resolution: time.Millisecond})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	c := New(cfg)
+	c.Start()
+	xclock.SetDefault(c)
+	return func() {
+		xclock.SetDefault(prev)
+		c.Stop()
+	}
+}
+
+// Use applies a started coarse clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	c := New(cfg)
+	c.Start()
+	xclock.SetDefault(c)
+}
+
+// With runs fn with a coarse clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// Stats reports updater health.
+type Stats struct {
+	// Running reports whether the updater is active.
+	Running bool
+	// Refreshes counts cache updates since construction.
+	Refreshes uint64
+	// Overruns counts refreshes that came more than MaxStale after the previous one.
+	Overruns uint64
+	// MaxGap is the longest observed interval between refreshes.
+	MaxGap time.Duration
+	// Fallbacks counts Now() calls that found the cache older than MaxStale and
+	// read the base (BoundStaleness only).
+	Fallbacks uint64
+}
+
+type Clock struct {
+	base       xclock.Clock
+	resolution time.Duration
+	maxStale   time.Duration
+	bounded    bool
+
+	cached  atomic.Pointer[entry]     // nil while stopped
+	floor   atomic.Pointer[time.Time] // last cached value, kept by Stop
+	running atomic.Bool
+
+	refreshes atomic.Uint64
+	overruns  atomic.Uint64
+	maxGap    atomic.Int64
+	fallbacks atomic.Uint64
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New constructs a coarse clock. The updater does not run until Start.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Resolution <= 0 {
+		cfg.Resolution = time.Millisecond
+	}
+	if cfg.MaxStale <= 0 {
+		cfg.MaxStale = 2 * cfg.Resolution
+	}
+	return &Clock{base: cfg.Base, resolution: cfg.Resolution, maxStale: cfg.MaxStale, bounded: cfg.BoundStaleness}
+}
+
+// entry is a cached value and the base reading it was refreshed at.
+type entry struct {
+	t  time.Time // value returned by Now (never earlier than the previous one)
+	at time.Time // base.Now() at refresh, for the staleness check
+}
+
+// Start launches the updater goroutine. Calling Start on a running clock is a no-op.
+func (c *Clock) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	c.refresh(true)
+	c.running.Store(true)
+	go c.update(c.stop, c.done)
+}
+
+// Stop ends the updater and waits for it to exit. Now() reads the base clock
+// afterwards, never returning less than the last cached value. Safe to call
+// multiple times.
+func (c *Clock) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	c.stop, c.done = nil, nil
+	c.running.Store(false)
+	if e := c.cached.Swap(nil); e != nil {
+		c.floor.Store(&e.t)
+	}
+}
+
+// Resolution returns the configured refresh interval.
+func (c *Clock) Resolution() time.Duration { return c.resolution }
+
+// Stats returns a snapshot of updater health.
+func (c *Clock) Stats() Stats {
+	return Stats{
+		Running:   c.running.Load(),
+		Refreshes: c.refreshes.Load(),
+		Overruns:  c.overruns.Load(),
+		MaxGap:    time.Duration(c.maxGap.Load()),
+		Fallbacks: c.fallbacks.Load(),
+	}
+}
+
+func (c *Clock) update(stop, done chan struct{}) {
+	defer close(done)
+	tk := c.base.NewTicker(c.resolution)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C():
+			c.refresh(false)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// refresh caches base.Now() and returns the cached value. Only start stores
+// into an empty cache, so a refresh racing Stop cannot revive it.
+func (c *Clock) refresh(start bool) time.Time {
+	at := c.base.Now()
+	for {
+		prev := c.cached.Load()
+		if prev == nil && !start {
+			return c.clamp(at)
+		}
+		e := &entry{t: c.clamp(at), at: at}
+		var gap time.Duration
+		if prev != nil {
+			if e.t.Before(prev.t) {
+				e.t = prev.t // never go backwards
+			}
+			gap = e.t.Sub(prev.t)
+		}
+		if !c.cached.CompareAndSwap(prev, e) {
+			continue
+		}
+		if prev != nil {
+			if gap > c.maxStale {
+				c.overruns.Add(1)
+			}
+			if int64(gap) > c.maxGap.Load() {
+				c.maxGap.Store(int64(gap))
+			}
+		}
+		c.refreshes.Add(1)
+		return e.t
+	}
+}
+
+// clamp raises t to the value cached when the updater last stopped.
+func (c *Clock) clamp(t time.Time) time.Time {
+	if f := c.floor.Load(); f != nil && t.Before(*f) {
+		return *f
+	}
+	return t
+}
+
+// Now returns the cached time, or base.Now() while the updater is stopped (held
+// at the last cached value while the base is behind it).
+// Under BoundStaleness a cached value older than MaxStale is refreshed first.
+func (c *Clock) Now() time.Time {
+	e := c.cached.Load()
+	if e == nil {
+		return c.clamp(c.base.Now())
+	}
+	if c.bounded && c.base.Since(e.at) > c.maxStale {
+		c.fallbacks.Add(1)
+		return c.refresh(false)
+	}
+	return e.t
+}
+
+func (c *Clock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration)           { c.base.Sleep(d) }
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.base.After(d)
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(d, f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
@@ -0,0 +1,298 @@
+package drift
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/internal/splitmix"
+)
+
+// Drift clock: simulates an imperfect oscillator. A frequency error in ppm makes
+// Now() run fast or slow and stretches timer/ticker durations accordingly; scripted
+// steps jump Now() like an NTP step; an optional random walk makes the frequency
+// wander over time. For stress-testing calibration and lease logic.
+//
+// Notes:
+// - Positive PPM runs fast: after 1s of base time Now() advanced 1s×(1+PPM/1e6),
+//   and a 1s timer fires after 1s/(1+PPM/1e6) of base time.
+// - Steps only move Now() (wall time), like a real clock step; durations of
+//   timers and tickers are unaffected, as with monotonic runtime timers.
+// - Timer durations use the frequency at the time they are (re)armed. Ticks are
+//   deadlines on the drifted timeline without steps, re-armed by SetPPM and
+//   re-checked at every wander step, so running tickers follow rate changes.
+// - The wander model is deterministic for a given Seed and base-time sequence.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// PPM is the initial frequency error in parts per million. Positive runs fast.
+	PPM float64
+	// Steps are scripted jumps of Now(), applied once base time reaches
+	// construction time + Step.At.
+	Steps []Step
+	// Wander is the standard deviation, in ppm, of the random-walk frequency change
+	// applied every WanderInterval. 0 disables wander.
+	Wander float64
+	// WanderInterval is the random-walk step period in base time. Defaults to 1s.
+	WanderInterval time.Duration
+	// MaxPPM bounds |frequency error| under wander. Defaults to 500.
+	MaxPPM float64
+	// Seed initializes the wander PRNG. If 0, it's seeded from time.Now().UnixNano().
+	Seed uint64
+}
+
+// Step jumps Now() by Jump once At of base time has elapsed since construction.
+type Step struct {
+	At   time.Duration
+	Jump time.Duration
+}
+
+// Set sets the drift clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := drift.Set(drift.Config{PPM: 50, Steps: []drift.Step{{At: 10 * time.Minute, Jump: 2 * time.Second}}})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the drift clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the drift clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+type Clock struct {
+	base     xclock.Clock
+	start    time.Time
+	wander   float64
+	interval time.Duration
+	maxPPM   float64
+	rng      *splitmix.Rand
+
+	mu         sync.Mutex
+	ppm        float64
+	anchorBase time.Time // base time of the last rate change or step
+	anchorNow  time.Time // drifted time at anchorBase
+	steps      []Step    // pending, sorted by At
+	nextWander time.Duration
+	stepped    time.Duration // sum of applied steps
+
+	wall xclock.WallTimers // tickers
+}
+
+// New constructs a drift clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.WanderInterval <= 0 {
+		cfg.WanderInterval = time.Second
+	}
+	if cfg.MaxPPM <= 0 {
+		cfg.MaxPPM = 500
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = uint64(time.Now().UnixNano())
+	}
+	now := cfg.Base.Now()
+	steps := slices.Clone(cfg.Steps)
+	slices.SortStableFunc(steps, func(a, b Step) int { return cmp.Compare(a.At, b.At) })
+	return &Clock{
+		base:       cfg.Base,
+		start:      now,
+		wander:     cfg.Wander,
+		interval:   cfg.WanderInterval,
+		maxPPM:     cfg.MaxPPM,
+		rng:        splitmix.New(cfg.Seed),
+		ppm:        cfg.PPM,
+		anchorBase: now,
+		anchorNow:  now,
+		steps:      steps,
+		nextWander: cfg.WanderInterval,
+	}
+}
+
+// Now returns the drifted time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.base.Now()
+	c.advanceLocked(now)
+	return c.driftedLocked(now)
+}
+
+// PPM returns the current frequency error.
+func (c *Clock) PPM() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceLocked(c.base.Now())
+	return c.ppm
+}
+
+// SetPPM changes the frequency error from now on.
+func (c *Clock) SetPPM(ppm float64) {
+	c.mu.Lock()
+	now := c.base.Now()
+	c.advanceLocked(now)
+	c.reanchorLocked(now)
+	c.ppm = ppm
+	c.mu.Unlock()
+	c.wall.Rearm()
+}
+
+// Step jumps Now() by d immediately.
+func (c *Clock) Step(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.base.Now()
+	c.advanceLocked(now)
+	c.reanchorLocked(now)
+	c.anchorNow = c.anchorNow.Add(d)
+	c.stepped += d
+}
+
+// Offset returns the accumulated difference between drifted and base time.
+func (c *Clock) Offset() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.base.Now()
+	c.advanceLocked(now)
+	return c.driftedLocked(now).Sub(now)
+}
+
+func (c *Clock) rate() float64 { return 1 + c.ppm/1e6 }
+
+func (c *Clock) driftedLocked(baseNow time.Time) time.Time {
+	el := baseNow.Sub(c.anchorBase)
+	return c.anchorNow.Add(time.Duration(float64(el) * c.rate()))
+}
+
+// reanchorLocked integrates the current rate up to baseNow.
+func (c *Clock) reanchorLocked(baseNow time.Time) {
+	c.anchorNow = c.driftedLocked(baseNow)
+	c.anchorBase = baseNow
+}
+
+// advanceLocked applies scripted steps and wander steps due by baseNow, in order.
+func (c *Clock) advanceLocked(baseNow time.Time) {
+	el := baseNow.Sub(c.start)
+	for {
+		stepDue := len(c.steps) > 0 && c.steps[0].At <= el
+		wanderDue := c.wander > 0 && c.nextWander <= el
+		switch {
+		case stepDue && (!wanderDue || c.steps[0].At <= c.nextWander):
+			s := c.steps[0]
+			c.steps = c.steps[1:]
+			c.reanchorLocked(c.start.Add(s.At))
+			c.anchorNow = c.anchorNow.Add(s.Jump)
+			c.stepped += s.Jump
+		case wanderDue:
+			c.reanchorLocked(c.start.Add(c.nextWander))
+			c.ppm += c.wander * c.gaussian()
+			c.ppm = math.Max(-c.maxPPM, math.Min(c.maxPPM, c.ppm))
+			c.nextWander += c.interval
+		default:
+			return
+		}
+	}
+}
+
+// gaussian returns a standard normal sample (Box-Muller).
+func (c *Clock) gaussian() float64 {
+	u1 := c.rng.Float64()
+	for u1 == 0 {
+		u1 = c.rng.Float64()
+	}
+	u2 := c.rng.Float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+// real converts a drifted duration to base duration at the current frequency.
+func (c *Clock) real(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	c.mu.Lock()
+	c.advanceLocked(c.base.Now())
+	r := c.rate()
+	c.mu.Unlock()
+	if out := time.Duration(float64(d) / r); out > 0 {
+		return out
+	}
+	return 1
+}
+
+func (c *Clock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration)           { c.base.Sleep(c.real(d)) }
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.base.After(c.real(d))
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(c.real(d), f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	return &timer{c: c, t: c.base.NewTimer(c.real(d))}
+}
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	return c.wall.NewTicker(steadyClock{c}, d)
+}
+
+type timer struct {
+	c *Clock
+	t xclock.Timer
+}
+
+func (t *timer) C() <-chan time.Time        { return t.t.C() }
+func (t *timer) Stop() bool                 { return t.t.Stop() }
+func (t *timer) Reset(d time.Duration) bool { return t.t.Reset(t.c.real(d)) }
+
+// steadyClock is the drift clock without steps, the timeline tickers run on.
+// Its AfterFunc waits at most until the next wander step, where the wall ticker
+// re-checks its deadline at the new frequency.
+type steadyClock struct{ c *Clock }
+
+func (s steadyClock) Now() time.Time {
+	c := s.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.base.Now()
+	c.advanceLocked(now)
+	return c.driftedLocked(now).Add(-c.stepped)
+}
+func (s steadyClock) Since(t time.Time) time.Duration { return s.Now().Sub(t) }
+func (s steadyClock) Sleep(d time.Duration)           { s.c.Sleep(d) }
+func (s steadyClock) After(d time.Duration) <-chan time.Time {
+	return s.c.After(d)
+}
+func (s steadyClock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return s.c.base.AfterFunc(s.c.untilWander(s.c.real(d)), f)
+}
+func (s steadyClock) NewTimer(d time.Duration) xclock.Timer   { return s.c.NewTimer(d) }
+func (s steadyClock) NewTicker(d time.Duration) xclock.Ticker { return s.c.NewTicker(d) }
+
+// untilWander caps a base duration at the time left until the next wander step.
+func (c *Clock) untilWander(d time.Duration) time.Duration {
+	if c.wander <= 0 || d <= 0 {
+		return d
+	}
+	c.mu.Lock()
+	left := c.start.Add(c.nextWander).Sub(c.base.Now())
+	c.mu.Unlock()
+	return max(min(d, left), 1)
+}
@@ -1,28 +1,64 @@
 package jitter
 
 import (
+	"math"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/internal/splitmix"
 )
 
-// Jitter clock: adds symmetric random jitter in [-MaxJitter, +MaxJitter]
-// to observed wall time returned by Now(). Scheduling delegates to the base clock.
+// Jitter clock: adds random jitter to observed wall time returned by Now().
+// Scheduling delegates to the base clock.
 //
 // Notes:
 // - Only Now() is jittered; Sleep/After/Timers/Tickers use the base clock.
 // - RNG is a lock-free SplitMix64 step on each Now() via atomic.Uint64.
-// - MaxJitter <= 0 disables jitter (returns base as-is).
+// - Samples are drawn from the configured Distribution, clamped to
+//   [-MaxJitter, +MaxJitter], then shifted by Bias.
+// - One clock shares one PRNG, so with several goroutines the draws each one
+//   sees depend on scheduling. Stream(key) derives an independent stream per key
+//   from the seed; give each goroutine its own key for reproducible tests.
+// - MaxJitter <= 0 disables jitter (New/NewWithSeed return base as-is).
+
+// Distribution selects the shape of the jitter.
+type Distribution int
+
+const (
+	// Uniform draws from [-MaxJitter, +MaxJitter].
+	Uniform Distribution = iota
+	// Normal draws from a normal distribution with standard deviation Scale.
+	Normal
+	// Laplace draws from a Laplace distribution with diversity Scale: a sharper
+	// peak and heavier tails than Normal.
+	Laplace
+	// Exponential draws a one-sided (non-negative) delay with mean Scale.
+	Exponential
+	// Pareto draws a one-sided delay from a Pareto distribution bounded to
+	// [Scale, MaxJitter] with shape Alpha: mostly small, occasionally large.
+	Pareto
+)
 
 type Config struct {
 	// Base is the underlying clock to wrap. If nil, xclock.Default() is used.
 	Base xclock.Clock
 	// MaxJitter is the maximum absolute jitter applied to Now().
-	// The actual jitter is uniformly distributed in [-MaxJitter, +MaxJitter].
+	// With the default Uniform distribution the jitter is uniformly distributed
+	// in [-MaxJitter, +MaxJitter]; other distributions are clamped to it.
 	MaxJitter time.Duration
 	// Seed initializes the PRNG. If 0, it's seeded from time.Now().UnixNano().
 	Seed uint64
+	// Distribution selects the jitter shape. Defaults to Uniform.
+	Distribution Distribution
+	// Scale parameterizes non-uniform distributions (see Distribution).
+	// Defaults to MaxJitter/3.
+	Scale time.Duration
+	// Alpha is the Pareto shape. Defaults to 1.5.
+	Alpha float64
+	// Bias is added to every sample, after clamping.
+	Bias time.Duration
 }
 
 // Set sets the jittered clock as the process-wide default and returns a restore
@@ -33,14 +69,14 @@ type Config struct {
 //	defer restore()
 func Set(cfg Config) (restore func()) {
 	prev := xclock.Default()
-	xclock.SetDefault(NewWithSeed(cfg.Base, cfg.MaxJitter, cfg.Seed))
+	xclock.SetDefault(NewClock(cfg))
 	return func() { xclock.SetDefault(prev) }
 }
 
 // Use applies the jittered clock without returning a restore function.
 // Recommended in production mains where you never intend to restore.
 func Use(cfg Config) {
-	xclock.SetDefault(NewWithSeed(cfg.Base, cfg.MaxJitter, cfg.Seed))
+	xclock.SetDefault(NewClock(cfg))
 }
 
 // With runs fn with the jittered clock active, then restores the previous clock
@@ -65,52 +101,168 @@ func NewWithSeed(base xclock.Clock, maxJitter time.Duration, seed uint64) xclock
 	if maxJitter <= 0 {
 		return base
 	}
-	j := &clock{base: base, maxJitter: maxJitter}
-	if seed == 0 {
-		seed = uint64(time.Now().UnixNano())
+	return NewClock(Config{Base: base, MaxJitter: maxJitter, Seed: seed})
+}
+
+// NewClock constructs a jitter clock from cfg. Unlike New, it always returns a
+// *Clock, so MaxJitter can start at 0 and be raised later with SetMaxJitter.
+func NewClock(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = uint64(time.Now().UnixNano())
+	}
+	if cfg.Alpha <= 0 {
+		cfg.Alpha = 1.5
 	}
-	j.seed.Store(seed)
+	sh := &shared{seed: cfg.Seed}
+	sh.params.Store(&params{
+		max:   cfg.MaxJitter,
+		dist:  cfg.Distribution,
+		scale: cfg.Scale,
+		alpha: cfg.Alpha,
+		bias:  cfg.Bias,
+	})
+	j := &Clock{base: cfg.Base, sh: sh}
+	j.rng.Seed(cfg.Seed)
 	return j
 }
 
-type clock struct {
-	base      xclock.Clock
-	maxJitter time.Duration
-	seed      atomic.Uint64 // SplitMix64 state
+// params are the runtime-adjustable settings shared by a clock and its streams.
+type params struct {
+	max   time.Duration
+	dist  Distribution
+	scale time.Duration
+	alpha float64
+	bias  time.Duration
 }
 
-func (j *clock) Now() time.Time {
+type shared struct {
+	params  atomic.Pointer[params]
+	seed    uint64
+	streams sync.Map // string -> *Clock
+}
+
+type Clock struct {
+	base xclock.Clock
+	rng  splitmix.Rand
+	sh   *shared
+}
+
+func (j *Clock) Now() time.Time {
 	baseNow := j.base.Now()
+	p := j.sh.params.Load()
+	if p.max <= 0 {
+		return baseNow.Add(p.bias)
+	}
+	return baseNow.Add(p.sample(&j.rng) + p.bias)
+}
+
+// sample draws one jitter value in [-max, +max].
+func (p *params) sample(rng *splitmix.Rand) time.Duration {
+	span := int64(p.max)
+	if p.dist == Uniform {
+		// Uniform in [-span, +span]
+		// Convert span to uint64 safely
+		uSpan := uint64(span)
+		return time.Duration(int64(rng.Uint64()%(2*uSpan+1)) - span)
+	}
+	scale := float64(p.scale)
+	if scale <= 0 {
+		scale = float64(p.max) / 3
+	}
+	var x float64
+	switch p.dist {
+	case Normal:
+		x = scale * gaussian(rng)
+	case Laplace:
+		u := rng.Float64() - 0.5
+		x = -scale * math.Copysign(math.Log(1-2*math.Abs(u)), u)
+	case Exponential:
+		x = -scale * math.Log(1-rng.Float64())
+	case Pareto:
+		lo, hi := scale, float64(p.max)
+		if lo >= hi {
+			lo = hi / 10
+		}
+		// Inverse CDF of the bounded Pareto distribution.
+		la, ha := math.Pow(lo, p.alpha), math.Pow(hi, p.alpha)
+		u := rng.Float64()
+		x = math.Pow(-(u*ha-u*la-ha)/(ha*la), -1/p.alpha)
+	}
+	x = math.Max(-float64(span), math.Min(float64(span), x))
+	return time.Duration(x)
+}
+
+// gaussian returns a standard normal sample (Box-Muller).
+func gaussian(rng *splitmix.Rand) float64 {
+	u1 := rng.Float64()
+	for u1 == 0 {
+		u1 = rng.Float64()
+	}
+	u2 := rng.Float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
 
-	span := int64(j.maxJitter)
-	if span <= 0 {
-		return baseNow
+// MaxJitter returns the current jitter bound.
+func (j *Clock) MaxJitter() time.Duration { return j.sh.params.Load().max }
+
+// SetMaxJitter changes the jitter bound for this clock and all its streams.
+// d <= 0 disables jitter (Bias still applies).
+func (j *Clock) SetMaxJitter(d time.Duration) {
+	for {
+		cur := j.sh.params.Load()
+		next := *cur
+		next.max = d
+		if j.sh.params.CompareAndSwap(cur, &next) {
+			return
+		}
 	}
+}
 
-	// SplitMix64: increment then mix
-	x := j.seed.Add(0x9e3779b97f4a7c15)
-	z := x
-	z ^= z >> 30
-	z *= 0xbf58476d1ce4e5b9
-	z ^= z >> 27
-	z *= 0x94d049bb133111eb
-	z ^= z >> 31
+// Stream returns the jitter stream for key: same base and settings, but its own
+// PRNG derived from the seed and key. The same seed and key always yield the same
+// sequence, regardless of what other streams or goroutines draw. Repeated calls
+// with the same key return the same stream.
+func (j *Clock) Stream(key string) *Clock {
+	if s, ok := j.sh.streams.Load(key); ok {
+		return s.(*Clock)
+	}
+	s := &Clock{base: j.base, sh: j.sh}
+	s.rng.Seed(deriveSeed(j.sh.seed, key))
+	actual, _ := j.sh.streams.LoadOrStore(key, s)
+	return actual.(*Clock)
+}
 
-	// Uniform in [-span, +span]
-	// Convert span to uint64 safely
-	uSpan := uint64(span)
-	jit := int64(z%(2*uSpan+1)) - span
+// deriveSeed mixes an FNV-1a hash of key into seed.
+func deriveSeed(seed uint64, key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+	var r splitmix.Rand
+	r.Seed(seed ^ h)
+	return r.Uint64()
+}
 
-	return baseNow.Add(time.Duration(jit))
+// Stream returns the keyed stream of the default clock when it is a jitter
+// clock, and the default clock itself otherwise.
+func Stream(key string) xclock.Clock {
+	if j, ok := xclock.Default().(*Clock); ok {
+		return j.Stream(key)
+	}
+	return xclock.Default()
 }
 
-func (j *clock) Since(t time.Time) time.Duration { return j.Now().Sub(t) }
-func (j *clock) Sleep(d time.Duration)           { j.base.Sleep(d) }
-func (j *clock) After(d time.Duration) <-chan time.Time {
+func (j *Clock) Since(t time.Time) time.Duration { return j.Now().Sub(t) }
+func (j *Clock) Sleep(d time.Duration)           { j.base.Sleep(d) }
+func (j *Clock) After(d time.Duration) <-chan time.Time {
 	return j.base.After(d)
 }
-func (j *clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+func (j *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
 	return j.base.AfterFunc(d, f)
 }
-func (j *clock) NewTimer(d time.Duration) xclock.Timer   { return j.base.NewTimer(d) }
-func (j *clock) NewTicker(d time.Duration) xclock.Ticker { return j.base.NewTicker(d) }
+func (j *Clock) NewTimer(d time.Duration) xclock.Timer   { return j.base.NewTimer(d) }
+func (j *Clock) NewTicker(d time.Duration) xclock.Ticker { return j.base.NewTicker(d) }
@@ -0,0 +1,322 @@
+package leakcheck
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Leakcheck clock: tracks every Timer, Ticker and AfterFunc created through it,
+// with its creation stack, so tests can fail on tickers that were never stopped
+// and timers still pending at the end. See xclocktest.VerifyNoLeaks.
+//
+// Notes:
+// - A timer (NewTimer, After) or AfterFunc is outstanding until it fires or is
+//   stopped; a ticker until it is stopped. Reset makes it outstanding again.
+// - Timers and AfterFuncs are built on base.AfterFunc so fires can be observed;
+//   tickers delegate to the base.
+// - Stacks are captured as program counters at creation and symbolized only
+//   when reported.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// StackDepth bounds captured creation stacks. Defaults to 32.
+	StackDepth int
+}
+
+// Set sets the leakcheck clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := leakcheck.Set(leakcheck.Config{})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the leakcheck clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the leakcheck clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// Kind is the type of a tracked resource.
+type Kind int
+
+const (
+	Timer Kind = iota
+	Ticker
+	AfterFunc
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Timer:
+		return "timer"
+	case Ticker:
+		return "ticker"
+	case AfterFunc:
+		return "AfterFunc"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Resource describes an outstanding timer, ticker or AfterFunc.
+type Resource struct {
+	Kind     Kind
+	ID       uint64        // creation order
+	Duration time.Duration // requested duration or period (latest Reset)
+	Created  time.Time     // base time of creation or latest Reset
+	Stack    string        // creation stack, innermost frame first
+}
+
+func (r Resource) String() string {
+	return fmt.Sprintf("%s #%d (%v) created at %s:\n%s", r.Kind, r.ID, r.Duration, r.Created.Format(time.RFC3339Nano), strings.TrimRight(r.Stack, "\n"))
+}
+
+type entry struct {
+	kind    Kind
+	id      uint64
+	d       time.Duration
+	created time.Time
+	pcs     []uintptr
+}
+
+type Clock struct {
+	base  xclock.Clock
+	depth int
+	ids   atomic.Uint64
+
+	mu          sync.Mutex
+	outstanding map[uint64]*entry
+}
+
+// New constructs a leakcheck clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.StackDepth <= 0 {
+		cfg.StackDepth = 32
+	}
+	return &Clock{base: cfg.Base, depth: cfg.StackDepth, outstanding: make(map[uint64]*entry)}
+}
+
+// newEntry captures the caller's stack; skip counts frames above newEntry to drop.
+func (c *Clock) newEntry(kind Kind, d time.Duration, skip int) *entry {
+	pcs := make([]uintptr, c.depth)
+	n := runtime.Callers(skip+2, pcs)
+	return &entry{kind: kind, id: c.ids.Add(1), d: d, created: c.base.Now(), pcs: pcs[:n]}
+}
+
+func (c *Clock) track(e *entry) {
+	c.mu.Lock()
+	c.outstanding[e.id] = e
+	c.mu.Unlock()
+}
+
+func (c *Clock) untrack(e *entry) {
+	c.mu.Lock()
+	delete(c.outstanding, e.id)
+	c.mu.Unlock()
+}
+
+// Outstanding returns the resources not yet fired or stopped, oldest first.
+func (c *Clock) Outstanding() []Resource {
+	c.mu.Lock()
+	list := make([]*entry, 0, len(c.outstanding))
+	for _, e := range c.outstanding {
+		list = append(list, e)
+	}
+	c.mu.Unlock()
+	slices.SortFunc(list, func(a, b *entry) int { return cmp.Compare(a.id, b.id) })
+
+	out := make([]Resource, len(list))
+	for i, e := range list {
+		out[i] = Resource{Kind: e.kind, ID: e.id, Duration: e.d, Created: e.created, Stack: formatStack(e.pcs)}
+	}
+	return out
+}
+
+// Check returns an error listing every outstanding resource, or nil.
+func (c *Clock) Check() error {
+	res := c.Outstanding()
+	if len(res) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "leakcheck: %d outstanding timers/tickers/AfterFuncs:", len(res))
+	for _, r := range res {
+		b.WriteString("\n\n")
+		b.WriteString(r.String())
+	}
+	return errors.New(b.String())
+}
+
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+func (c *Clock) Now() time.Time                  { return c.base.Now() }
+func (c *Clock) Since(t time.Time) time.Duration { return c.base.Since(t) }
+func (c *Clock) Sleep(d time.Duration)           { c.base.Sleep(d) }
+
+func (c *Clock) After(d time.Duration) <-chan time.Time { return c.newTimer(d).ch }
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	e := c.newEntry(AfterFunc, d, 1)
+	c.track(e)
+	cancel := c.base.AfterFunc(d, func() {
+		c.untrack(e)
+		f()
+	})
+	return func() bool {
+		ok := cancel()
+		if ok {
+			c.untrack(e)
+		}
+		return ok
+	}
+}
+
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer { return c.newTimer(d) }
+
+func (c *Clock) newTimer(d time.Duration) *timer {
+	// Skip newTimer and NewTimer/After.
+	t := &timer{c: c, e: c.newEntry(Timer, d, 2), ch: make(chan time.Time, 1)}
+	t.mu.Lock()
+	t.armLocked(d)
+	t.mu.Unlock()
+	return t
+}
+
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	t := &ticker{c: c, e: c.newEntry(Ticker, d, 1), t: c.base.NewTicker(d)}
+	c.track(t.e)
+	return t
+}
+
+// timer is a one-shot timer on base.AfterFunc, so firing can untrack it.
+type timer struct {
+	c  *Clock
+	e  *entry
+	ch chan time.Time
+
+	mu     sync.Mutex
+	gen    uint64
+	cancel xclock.CancelFunc // nil when not pending
+}
+
+func (t *timer) armLocked(d time.Duration) {
+	t.gen++
+	gen := t.gen
+	t.c.track(t.e)
+	t.cancel = t.c.base.AfterFunc(d, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if gen != t.gen {
+			return
+		}
+		t.cancel = nil
+		t.c.untrack(t.e)
+		select {
+		case t.ch <- t.c.base.Now():
+		default:
+		}
+	})
+}
+
+func (t *timer) stopLocked() bool {
+	t.gen++
+	if t.cancel == nil {
+		return false
+	}
+	ok := t.cancel()
+	t.cancel = nil
+	t.c.untrack(t.e)
+	return ok
+}
+
+func (t *timer) C() <-chan time.Time { return t.ch }
+
+func (t *timer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stopLocked()
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ok := t.stopLocked()
+	t.e = t.c.newEntry(Timer, d, 1)
+	t.armLocked(d)
+	return ok
+}
+
+type ticker struct {
+	c *Clock
+	t xclock.Ticker
+
+	mu sync.Mutex
+	e  *entry // nil once stopped
+}
+
+func (t *ticker) C() <-chan time.Time { return t.t.C() }
+
+func (t *ticker) Stop() {
+	t.t.Stop()
+	t.mu.Lock()
+	if t.e != nil {
+		t.c.untrack(t.e)
+		t.e = nil
+	}
+	t.mu.Unlock()
+}
+
+func (t *ticker) Reset(d time.Duration) {
+	t.t.Reset(d)
+	t.mu.Lock()
+	if t.e == nil {
+		t.e = t.c.newEntry(Ticker, d, 1)
+		t.c.track(t.e)
+	} else {
+		// Entries are read concurrently by Outstanding; replace, don't mutate.
+		e := *t.e
+		e.d = d
+		t.c.untrack(t.e)
+		t.e = &e
+		t.c.track(t.e)
+	}
+	t.mu.Unlock()
+}
@@ -0,0 +1,162 @@
+package leap
+
+import (
+	"math"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Leap clock: smears leap seconds over a window instead of stepping, as Google's
+// and AWS's time services do. During the window Now() runs slightly slow (or fast
+// for a negative leap) so that it absorbs the extra second and meets UTC again at
+// the end of the window.
+//
+// Notes:
+// - The base must count the leap second as ordinary elapsed time, i.e. it must not
+//   step back at 23:59:60: a frozen clock in tests, or a continuous
+//   source (TAI/GPS-derived) that agreed with UTC at Since.
+// - Windows are centered on the leap: 24h linear runs noon to noon UTC, taking
+//   86401 base seconds to cover 86400 UTC seconds.
+// - Only leaps whose window starts at or after Since are smeared.
+// - Timers, tickers and sleeps delegate to the base; the smear changes the rate by
+//   about 11.6ppm, well below timer precision.
+
+// Kind selects the smear curve.
+type Kind int
+
+const (
+	// Linear spreads the second evenly over the window.
+	Linear Kind = iota
+	// Cosine eases in and out, (1-cos(πx))/2, so the rate changes smoothly.
+	Cosine
+)
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Table is the leap-second table. If nil, Default() is used.
+	Table *Table
+	// Kind is the smear curve. Defaults to Linear.
+	Kind Kind
+	// Window is the smear duration, centered on the leap. Defaults to 24h.
+	Window time.Duration
+	// Since is the base time at which the base agreed with UTC. If zero, the base
+	// time at construction is used.
+	Since time.Time
+}
+
+// Set sets the leap clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := leap.Set(leap.Config{Base: frozen.New(t), Since: t})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the leap clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the leap clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// window is one smear in base time: the correction grows from 0 to step between
+// start and end.
+type window struct {
+	start time.Time
+	end   time.Time
+	step  time.Duration
+}
+
+type Clock struct {
+	base    xclock.Clock
+	table   *Table
+	kind    Kind
+	windows []window
+}
+
+// New constructs a leap-smearing clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Table == nil {
+		cfg.Table = Default()
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 24 * time.Hour
+	}
+	if cfg.Since.IsZero() {
+		cfg.Since = cfg.Base.Now()
+	}
+	c := &Clock{base: cfg.Base, table: cfg.Table, kind: cfg.Kind}
+
+	// Each smeared leap shifts later windows by its step in base time.
+	var shift time.Duration
+	for i, l := range cfg.Table.leaps {
+		step := cfg.Table.step(i)
+		start := l.At.Add(-cfg.Window / 2)
+		if step == 0 || start.Before(cfg.Since) {
+			continue
+		}
+		c.windows = append(c.windows, window{
+			start: start.Add(shift),
+			end:   l.At.Add(cfg.Window/2 + shift + step),
+			step:  step,
+		})
+		shift += step
+	}
+	return c
+}
+
+// Now returns base time with pending leap seconds smeared out.
+func (c *Clock) Now() time.Time {
+	b := c.base.Now()
+	return b.Add(-c.correction(b))
+}
+
+// Smear returns the current correction: how far Now() is behind the base.
+func (c *Clock) Smear() time.Duration { return c.correction(c.base.Now()) }
+
+// Table returns the clock's leap-second table.
+func (c *Clock) Table() *Table { return c.table }
+
+func (c *Clock) correction(b time.Time) time.Duration {
+	var d time.Duration
+	for _, w := range c.windows {
+		if !b.After(w.start) {
+			break
+		}
+		if !b.Before(w.end) {
+			d += w.step
+			continue
+		}
+		x := float64(b.Sub(w.start)) / float64(w.end.Sub(w.start))
+		if c.kind == Cosine {
+			x = (1 - math.Cos(math.Pi*x)) / 2
+		}
+		d += time.Duration(x * float64(w.step))
+	}
+	return d
+}
+
+func (c *Clock) Since(t time.Time) time.Duration        { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration)                  { c.base.Sleep(d) }
+func (c *Clock) After(d time.Duration) <-chan time.Time { return c.base.After(d) }
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(d, f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
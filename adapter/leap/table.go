@@ -0,0 +1,239 @@
+package leap
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LeapSecond is one entry of a leap-second table.
+type LeapSecond struct {
+	// At is the UTC instant the new offset takes effect (00:00:00 after the leap).
+	At time.Time
+	// TAIOffset is TAI-UTC from At onwards.
+	TAIOffset time.Duration
+}
+
+// Table is an immutable, sorted leap-second table.
+type Table struct {
+	leaps   []LeapSecond
+	expires time.Time
+}
+
+// gpsTAIOffset is TAI-GPS, fixed since the GPS epoch (1980-01-06).
+const gpsTAIOffset = 19 * time.Second
+
+// ntpEpoch is the zero of NTP timestamps used by leap-seconds.list.
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+//go:embed leap-seconds.list
+var embedded []byte
+
+var (
+	defaultOnce  sync.Once
+	defaultTable *Table
+)
+
+// Default returns the embedded table, a verbatim copy of the IANA
+// leap-seconds.list. Check Expires and load a current copy with Parse once it
+// has passed.
+func Default() *Table {
+	defaultOnce.Do(func() {
+		t, err := Parse(bytes.NewReader(embedded))
+		if err != nil {
+			panic("leap: embedded table: " + err.Error())
+		}
+		defaultTable = t
+	})
+	return defaultTable
+}
+
+// ErrChecksum is returned when a table's "#h" hash does not match its contents.
+var ErrChecksum = errors.New("leap: hash mismatch")
+
+// Parse reads a table in the IANA leap-seconds.list format: lines of
+// "<NTP seconds> <TAI-UTC>" with '#' comments, an optional "#@ <NTP seconds>"
+// expiration line and "#$ <NTP seconds>" update line. If the file carries a
+// "#h" SHA-1 line, it is verified (ErrChecksum on mismatch); files without one
+// are accepted unchecked.
+func Parse(r io.Reader) (*Table, error) {
+	var (
+		leaps   []LeapSecond
+		expires time.Time
+		want    string
+	)
+	// The hash covers the #$ and #@ values and the two fields of every data line,
+	// concatenated without separators.
+	sum := sha1.New()
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if rest, ok := strings.CutPrefix(line, "#h"); ok {
+			want = strings.Join(strings.Fields(rest), "")
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "#$"); ok {
+			v := strings.TrimSpace(rest)
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("leap: line %d: bad update time: %w", n, err)
+			}
+			sum.Write([]byte(v))
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "#@"); ok {
+			v := strings.TrimSpace(rest)
+			secs, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("leap: line %d: bad expiration: %w", n, err)
+			}
+			sum.Write([]byte(v))
+			expires = ntpEpoch.Add(time.Duration(secs) * time.Second)
+			continue
+		}
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("leap: line %d: expected 2 fields, found %d", n, len(fields))
+		}
+		secs, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("leap: line %d: bad timestamp: %w", n, err)
+		}
+		off, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("leap: line %d: bad offset: %w", n, err)
+		}
+		sum.Write([]byte(fields[0] + fields[1]))
+		leaps = append(leaps, LeapSecond{
+			At:        ntpEpoch.Add(time.Duration(secs) * time.Second),
+			TAIOffset: time.Duration(off) * time.Second,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("leap: %w", err)
+	}
+	if len(leaps) == 0 {
+		return nil, fmt.Errorf("leap: table has no entries")
+	}
+	if want != "" && !strings.EqualFold(want, hex.EncodeToString(sum.Sum(nil))) {
+		return nil, ErrChecksum
+	}
+	return NewTable(leaps, expires), nil
+}
+
+// NewTable builds a table from entries, e.g. to simulate a future leap second in
+// tests. Entries are sorted by At; expires may be zero.
+func NewTable(leaps []LeapSecond, expires time.Time) *Table {
+	l := slices.Clone(leaps)
+	slices.SortFunc(l, func(a, b LeapSecond) int { return a.At.Compare(b.At) })
+	return &Table{leaps: l, expires: expires}
+}
+
+// Leaps returns a copy of the entries, oldest first.
+func (t *Table) Leaps() []LeapSecond { return slices.Clone(t.leaps) }
+
+// Expires returns the table's expiration, or the zero time if unknown.
+func (t *Table) Expires() time.Time { return t.expires }
+
+// Expired reports whether the table may be missing leap seconds announced after now.
+func (t *Table) Expired(now time.Time) bool {
+	return !t.expires.IsZero() && now.After(t.expires)
+}
+
+// Next returns the first leap second taking effect after utc.
+func (t *Table) Next(utc time.Time) (LeapSecond, bool) {
+	i, found := slices.BinarySearchFunc(t.leaps, utc, func(l LeapSecond, u time.Time) int { return l.At.Compare(u) })
+	if found {
+		i++
+	}
+	if i >= len(t.leaps) {
+		return LeapSecond{}, false
+	}
+	return t.leaps[i], true
+}
+
+// TAIOffset returns TAI-UTC at utc. Before the first entry the first offset is used.
+func (t *Table) TAIOffset(utc time.Time) time.Duration {
+	i, found := slices.BinarySearchFunc(t.leaps, utc, func(l LeapSecond, u time.Time) int { return l.At.Compare(u) })
+	if !found {
+		i--
+	}
+	if i < 0 {
+		i = 0
+	}
+	return t.leaps[i].TAIOffset
+}
+
+// step returns the size of leap i (+1s for an inserted second).
+func (t *Table) step(i int) time.Duration {
+	if i == 0 {
+		return 0
+	}
+	return t.leaps[i].TAIOffset - t.leaps[i-1].TAIOffset
+}
+
+// TAI and GPS times are represented as time.Time values in UTC whose fields
+// carry the TAI or GPS labels; they are not UTC instants.
+
+// UTCToTAI converts a UTC time to TAI.
+func (t *Table) UTCToTAI(utc time.Time) time.Time {
+	return utc.UTC().Add(t.TAIOffset(utc))
+}
+
+// TAIToUTC converts a TAI time to UTC. TAI times inside an inserted leap second
+// (23:59:60 UTC) map onto a repeated 23:59:59.
+func (t *Table) TAIToUTC(tai time.Time) time.Time {
+	tai = tai.UTC()
+	for i := len(t.leaps) - 1; i > 0; i-- {
+		l := t.leaps[i]
+		// The new offset applies from the start of the inserted second, so
+		// 23:59:60 becomes 23:59:59 again.
+		if !tai.Before(l.At.Add(l.TAIOffset - max(t.step(i), 0))) {
+			return tai.Add(-l.TAIOffset)
+		}
+	}
+	return tai.Add(-t.leaps[0].TAIOffset)
+}
+
+// UTCToGPS converts a UTC time to GPS time (TAI - 19s).
+func (t *Table) UTCToGPS(utc time.Time) time.Time {
+	return t.UTCToTAI(utc).Add(-gpsTAIOffset)
+}
+
+// GPSToUTC converts a GPS time to UTC.
+func (t *Table) GPSToUTC(gps time.Time) time.Time {
+	return t.TAIToUTC(gps.UTC().Add(gpsTAIOffset))
+}
+
+// TAIToGPS converts TAI to GPS time.
+func TAIToGPS(tai time.Time) time.Time { return tai.UTC().Add(-gpsTAIOffset) }
+
+// GPSToTAI converts GPS time to TAI.
+func GPSToTAI(gps time.Time) time.Time { return gps.UTC().Add(gpsTAIOffset) }
+
+// UTCToTAI converts utc to TAI using the Default table.
+func UTCToTAI(utc time.Time) time.Time { return Default().UTCToTAI(utc) }
+
+// TAIToUTC converts tai to UTC using the Default table.
+func TAIToUTC(tai time.Time) time.Time { return Default().TAIToUTC(tai) }
+
+// UTCToGPS converts utc to GPS time using the Default table.
+func UTCToGPS(utc time.Time) time.Time { return Default().UTCToGPS(utc) }
+
+// GPSToUTC converts gps to UTC using the Default table.
+func GPSToUTC(gps time.Time) time.Time { return Default().GPSToUTC(gps) }
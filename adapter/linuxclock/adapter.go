@@ -0,0 +1,224 @@
+package linuxclock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Linux clock: Now() reads clock_gettime for a selectable kernel clock, exposing
+// the difference between suspend-aware, raw-oscillator, coarse and TAI clocks
+// that time.Now hides. Scheduling delegates to the base clock.
+//
+// Notes:
+// - Realtime, RealtimeCoarse and TAI count from the Unix epoch and are returned
+//   as is. TAI times carry TAI labels in a UTC time.Time; they are only TAI if
+//   the kernel's TAI offset is set (see Adjtimex).
+// - Monotonic, MonotonicCoarse, MonotonicRaw and Boottime count from an
+//   arbitrary point (boot). Now() anchors them to base.Now() at construction and
+//   advances at the selected clock's rate: MonotonicRaw ignores NTP frequency
+//   slewing, Boottime keeps counting during suspend. Raw returns the unanchored
+//   reading.
+// - Returned times carry no monotonic reading.
+// - On other platforms New returns an error wrapping errors.ErrUnsupported.
+
+// ID selects the kernel clock.
+type ID int
+
+const (
+	Realtime ID = iota
+	RealtimeCoarse
+	Monotonic
+	MonotonicCoarse
+	MonotonicRaw
+	Boottime
+	TAI
+)
+
+func (id ID) String() string {
+	switch id {
+	case Realtime:
+		return "CLOCK_REALTIME"
+	case RealtimeCoarse:
+		return "CLOCK_REALTIME_COARSE"
+	case Monotonic:
+		return "CLOCK_MONOTONIC"
+	case MonotonicCoarse:
+		return "CLOCK_MONOTONIC_COARSE"
+	case MonotonicRaw:
+		return "CLOCK_MONOTONIC_RAW"
+	case Boottime:
+		return "CLOCK_BOOTTIME"
+	case TAI:
+		return "CLOCK_TAI"
+	}
+	return fmt.Sprintf("ID(%d)", int(id))
+}
+
+// epoch reports whether the clock counts from the Unix epoch.
+func (id ID) epoch() bool { return id == Realtime || id == RealtimeCoarse || id == TAI }
+
+type Config struct {
+	// Base schedules timers and sleeps and anchors boot-relative clocks.
+	// If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// ID is the kernel clock to read. Defaults to Realtime.
+	ID ID
+}
+
+// Set sets the Linux clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore, err := linuxclock.Set(linuxclock.Config{ID: linuxclock.MonotonicRaw})
+//	if err != nil { ... }
+//	defer restore()
+func Set(cfg Config) (restore func(), err error) {
+	c, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	prev := xclock.Default()
+	xclock.SetDefault(c)
+	return func() { xclock.SetDefault(prev) }, nil
+}
+
+// Use applies the Linux clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) error {
+	c, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	xclock.SetDefault(c)
+	return nil
+}
+
+// With runs fn with the Linux clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding). If the clock cannot
+// be read, fn is not run.
+func With(cfg Config, fn func()) error {
+	restore, err := Set(cfg)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fn()
+	return nil
+}
+
+type Clock struct {
+	base xclock.Clock
+	id   ID
+
+	// Anchor for boot-relative clocks.
+	wall time.Time
+	raw  time.Duration
+}
+
+// New constructs a Linux clock. It fails if the kernel does not support id.
+func New(cfg Config) (*Clock, error) {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	raw, err := gettime(cfg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("linuxclock: %v: %w", cfg.ID, err)
+	}
+	c := &Clock{base: cfg.Base, id: cfg.ID}
+	if !cfg.ID.epoch() {
+		c.wall = cfg.Base.Now().Round(0)
+		c.raw = raw
+	}
+	return c, nil
+}
+
+// ID returns the kernel clock being read.
+func (c *Clock) ID() ID { return c.id }
+
+// Raw returns the clock's current reading since its own epoch (the Unix epoch,
+// or boot for boot-relative clocks).
+func (c *Clock) Raw() time.Duration {
+	raw, err := gettime(c.id)
+	if err != nil {
+		// Checked in New; clock_gettime does not fail for a supported ID.
+		panic("linuxclock: " + err.Error())
+	}
+	return raw
+}
+
+// Now returns the selected clock's time (anchored for boot-relative clocks).
+func (c *Clock) Now() time.Time {
+	raw := c.Raw()
+	if c.id.epoch() {
+		return time.Unix(0, int64(raw))
+	}
+	return c.wall.Add(raw - c.raw)
+}
+
+func (c *Clock) Since(t time.Time) time.Duration        { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration)                  { c.base.Sleep(d) }
+func (c *Clock) After(d time.Duration) <-chan time.Time { return c.base.After(d) }
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(d, f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
+
+// State is the kernel clock state returned by adjtimex.
+type State int
+
+const (
+	StateOK    State = iota // clock synchronized, no leap second pending
+	StateIns                // insert leap second at end of day
+	StateDel                // delete leap second at end of day
+	StateOOP                // leap second in progress
+	StateWait               // leap second has occurred
+	StateError              // clock not synchronized
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOK:
+		return "OK"
+	case StateIns:
+		return "INS"
+	case StateDel:
+		return "DEL"
+	case StateOOP:
+		return "OOP"
+	case StateWait:
+		return "WAIT"
+	case StateError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Status is a read-only snapshot of the kernel's NTP discipline (adjtimex).
+type Status struct {
+	State State
+	// Synced is false when the kernel flags the clock unsynchronized (STA_UNSYNC).
+	Synced bool
+	// Offset is the current time offset being corrected.
+	Offset time.Duration
+	// Frequency is the frequency correction in ppm.
+	Frequency float64
+	// MaxError and EstError are the maximum and estimated errors.
+	MaxError time.Duration
+	EstError time.Duration
+	// TAIOffset is TAI-UTC as set by the NTP daemon; 0 if never set.
+	TAIOffset time.Duration
+	// StatusBits are the raw STA_* flags.
+	StatusBits int
+}
+
+// Adjtimex reads the kernel's clock discipline status without modifying it.
+func Adjtimex() (Status, error) {
+	s, err := adjtimex()
+	if err != nil {
+		return Status{}, fmt.Errorf("linuxclock: adjtimex: %w", err)
+	}
+	return s, nil
+}
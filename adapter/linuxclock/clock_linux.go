@@ -0,0 +1,52 @@
+//go:build linux
+
+package linuxclock
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var clockIDs = [...]int32{
+	Realtime:        unix.CLOCK_REALTIME,
+	RealtimeCoarse:  unix.CLOCK_REALTIME_COARSE,
+	Monotonic:       unix.CLOCK_MONOTONIC,
+	MonotonicCoarse: unix.CLOCK_MONOTONIC_COARSE,
+	MonotonicRaw:    unix.CLOCK_MONOTONIC_RAW,
+	Boottime:        unix.CLOCK_BOOTTIME,
+	TAI:             unix.CLOCK_TAI,
+}
+
+func gettime(id ID) (time.Duration, error) {
+	if id < 0 || int(id) >= len(clockIDs) {
+		return 0, unix.EINVAL
+	}
+	var ts unix.Timespec
+	if err := unix.ClockGettime(clockIDs[id], &ts); err != nil {
+		return 0, err
+	}
+	return time.Duration(ts.Nano()), nil
+}
+
+func adjtimex() (Status, error) {
+	var tx unix.Timex // Modes == 0: read only
+	state, err := unix.Adjtimex(&tx)
+	if err != nil {
+		return Status{}, err
+	}
+	unit := time.Microsecond
+	if tx.Status&unix.STA_NANO != 0 {
+		unit = time.Nanosecond
+	}
+	return Status{
+		State:      State(state),
+		Synced:     tx.Status&unix.STA_UNSYNC == 0,
+		Offset:     time.Duration(tx.Offset) * unit,
+		Frequency:  float64(tx.Freq) / 65536,
+		MaxError:   time.Duration(tx.Maxerror) * time.Microsecond,
+		EstError:   time.Duration(tx.Esterror) * time.Microsecond,
+		TAIOffset:  time.Duration(tx.Tai) * time.Second,
+		StatusBits: int(tx.Status),
+	}, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package linuxclock
+
+import (
+	"errors"
+	"time"
+)
+
+func gettime(ID) (time.Duration, error) { return 0, errors.ErrUnsupported }
+
+func adjtimex() (Status, error) { return Status{}, errors.ErrUnsupported }
@@ -0,0 +1,299 @@
+package metrics
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Metrics clock: counts how a service uses time. Tracks Now()/Since() call
+// counts, outstanding timers, tickers and AfterFuncs, AfterFunc fired versus
+// cancelled, and how far sleeps, timers and AfterFuncs overshoot the requested
+// duration. Export through Prometheus text format, expvar, or any Exporter.
+//
+// Notes:
+// - Counters and histograms are atomics; Now, Since and Sleep take no locks.
+// - Overshoot is measured with base.Now() around the wait, so it includes the
+//   scheduling delay until the callback or sleeper runs.
+// - Timers (NewTimer, After) and AfterFuncs are built on base.AfterFunc so fires
+//   can be observed; tickers delegate to the base and are only counted.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Namespace prefixes exported metric names. Defaults to "xclock".
+	Namespace string
+	// Buckets are the overshoot histogram upper bounds, in any order; duplicates
+	// are dropped. Defaults to DefaultBuckets.
+	Buckets []time.Duration
+}
+
+// Set sets the metrics clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := metrics.Set(metrics.Config{})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the metrics clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+// To export, keep the clock instead:
+//
+//	m := metrics.New(metrics.Config{})
+//	xclock.SetDefault(m)
+//	http.Handle("/metrics", m.Handler())
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the metrics clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+type Clock struct {
+	base xclock.Clock
+	ns   string
+
+	nowCalls   atomic.Uint64
+	sinceCalls atomic.Uint64
+	sleeps     atomic.Uint64
+
+	timersCreated     atomic.Uint64
+	timersFired       atomic.Uint64
+	timersStopped     atomic.Uint64
+	timersOutstanding atomic.Int64
+
+	tickersCreated     atomic.Uint64
+	tickersOutstanding atomic.Int64
+
+	funcsCreated   atomic.Uint64
+	funcsFired     atomic.Uint64
+	funcsCancelled atomic.Uint64
+	funcsPending   atomic.Int64
+
+	sleepOvershoot *Histogram
+	timerOvershoot *Histogram
+	funcOvershoot  *Histogram
+}
+
+// New constructs a metrics clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = "xclock"
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultBuckets
+	}
+	// Observe needs ascending bounds; the copy also keeps later changes to the
+	// caller's slice out of the histograms.
+	cfg.Buckets = slices.Clone(cfg.Buckets)
+	slices.Sort(cfg.Buckets)
+	cfg.Buckets = slices.Compact(cfg.Buckets)
+	return &Clock{
+		base:           cfg.Base,
+		ns:             cfg.Namespace,
+		sleepOvershoot: newHistogram(cfg.Buckets),
+		timerOvershoot: newHistogram(cfg.Buckets),
+		funcOvershoot:  newHistogram(cfg.Buckets),
+	}
+}
+
+// Snapshot is a point-in-time copy of all metrics.
+type Snapshot struct {
+	NowCalls   uint64
+	SinceCalls uint64
+	Sleeps     uint64
+
+	TimersCreated     uint64
+	TimersFired       uint64
+	TimersStopped     uint64
+	TimersOutstanding int64
+
+	TickersCreated     uint64
+	TickersOutstanding int64
+
+	AfterFuncsCreated   uint64
+	AfterFuncsFired     uint64
+	AfterFuncsCancelled uint64
+	AfterFuncsPending   int64
+
+	SleepOvershoot     HistogramSnapshot
+	TimerOvershoot     HistogramSnapshot
+	AfterFuncOvershoot HistogramSnapshot
+}
+
+// Snapshot returns the current metrics.
+func (c *Clock) Snapshot() Snapshot {
+	return Snapshot{
+		NowCalls:            c.nowCalls.Load(),
+		SinceCalls:          c.sinceCalls.Load(),
+		Sleeps:              c.sleeps.Load(),
+		TimersCreated:       c.timersCreated.Load(),
+		TimersFired:         c.timersFired.Load(),
+		TimersStopped:       c.timersStopped.Load(),
+		TimersOutstanding:   c.timersOutstanding.Load(),
+		TickersCreated:      c.tickersCreated.Load(),
+		TickersOutstanding:  c.tickersOutstanding.Load(),
+		AfterFuncsCreated:   c.funcsCreated.Load(),
+		AfterFuncsFired:     c.funcsFired.Load(),
+		AfterFuncsCancelled: c.funcsCancelled.Load(),
+		AfterFuncsPending:   c.funcsPending.Load(),
+		SleepOvershoot:      c.sleepOvershoot.Snapshot(),
+		TimerOvershoot:      c.timerOvershoot.Snapshot(),
+		AfterFuncOvershoot:  c.funcOvershoot.Snapshot(),
+	}
+}
+
+func (c *Clock) Now() time.Time {
+	c.nowCalls.Add(1)
+	return c.base.Now()
+}
+
+func (c *Clock) Since(t time.Time) time.Duration {
+	c.sinceCalls.Add(1)
+	return c.base.Since(t)
+}
+
+func (c *Clock) Sleep(d time.Duration) {
+	c.sleeps.Add(1)
+	start := c.base.Now()
+	c.base.Sleep(d)
+	c.sleepOvershoot.Observe(c.base.Since(start) - max(d, 0))
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time { return c.NewTimer(d).C() }
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	c.funcsCreated.Add(1)
+	c.funcsPending.Add(1)
+	start := c.base.Now()
+	cancel := c.base.AfterFunc(d, func() {
+		c.funcOvershoot.Observe(c.base.Since(start) - max(d, 0))
+		c.funcsFired.Add(1)
+		c.funcsPending.Add(-1)
+		f()
+	})
+	return func() bool {
+		ok := cancel()
+		if ok {
+			c.funcsCancelled.Add(1)
+			c.funcsPending.Add(-1)
+		}
+		return ok
+	}
+}
+
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	c.timersCreated.Add(1)
+	t := &timer{c: c, ch: make(chan time.Time, 1)}
+	t.mu.Lock()
+	t.armLocked(d)
+	t.mu.Unlock()
+	return t
+}
+
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	c.tickersCreated.Add(1)
+	c.tickersOutstanding.Add(1)
+	return &ticker{c: c, t: c.base.NewTicker(d)}
+}
+
+// timer is a one-shot timer on base.AfterFunc, so its overshoot can be measured.
+type timer struct {
+	c  *Clock
+	ch chan time.Time
+
+	mu     sync.Mutex
+	gen    uint64
+	cancel xclock.CancelFunc // nil when not pending
+}
+
+func (t *timer) armLocked(d time.Duration) {
+	t.gen++
+	gen := t.gen
+	start := t.c.base.Now()
+	t.c.timersOutstanding.Add(1)
+	t.cancel = t.c.base.AfterFunc(d, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if gen != t.gen {
+			return
+		}
+		t.cancel = nil
+		now := t.c.base.Now()
+		t.c.timerOvershoot.Observe(now.Sub(start) - max(d, 0))
+		t.c.timersFired.Add(1)
+		t.c.timersOutstanding.Add(-1)
+		select {
+		case t.ch <- now:
+		default:
+		}
+	})
+}
+
+// stopLocked disarms the timer and reports whether it was pending.
+func (t *timer) stopLocked() bool {
+	t.gen++
+	if t.cancel == nil {
+		return false
+	}
+	ok := t.cancel()
+	t.cancel = nil
+	t.c.timersOutstanding.Add(-1)
+	return ok
+}
+
+func (t *timer) C() <-chan time.Time { return t.ch }
+
+func (t *timer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ok := t.stopLocked()
+	if ok {
+		t.c.timersStopped.Add(1)
+	}
+	return ok
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ok := t.stopLocked()
+	t.armLocked(d)
+	return ok
+}
+
+type ticker struct {
+	c       *Clock
+	t       xclock.Ticker
+	stopped atomic.Bool
+}
+
+func (t *ticker) C() <-chan time.Time { return t.t.C() }
+func (t *ticker) Reset(d time.Duration) {
+	t.t.Reset(d)
+	if t.stopped.CompareAndSwap(true, false) {
+		t.c.tickersOutstanding.Add(1)
+	}
+}
+func (t *ticker) Stop() {
+	t.t.Stop()
+	if t.stopped.CompareAndSwap(false, true) {
+		t.c.tickersOutstanding.Add(-1)
+	}
+}
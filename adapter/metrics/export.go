@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Exporter receives metrics from Clock.Export, one call per metric. Names are
+// fully qualified (namespace included) and follow Prometheus conventions.
+type Exporter interface {
+	Counter(name, help string, v uint64)
+	Gauge(name, help string, v int64)
+	Histogram(name, help string, h HistogramSnapshot)
+}
+
+// Export walks every metric into e.
+func (c *Clock) Export(e Exporter) {
+	s := c.Snapshot()
+	n := func(name string) string { return c.ns + "_" + name }
+	e.Counter(n("now_calls_total"), "Now() calls.", s.NowCalls)
+	e.Counter(n("since_calls_total"), "Since() calls.", s.SinceCalls)
+	e.Counter(n("sleeps_total"), "Sleep() calls.", s.Sleeps)
+	e.Counter(n("timers_created_total"), "Timers created by NewTimer and After.", s.TimersCreated)
+	e.Counter(n("timers_fired_total"), "Timer fires.", s.TimersFired)
+	e.Counter(n("timers_stopped_total"), "Timers stopped before firing.", s.TimersStopped)
+	e.Gauge(n("timers_outstanding"), "Timers armed and not yet fired or stopped.", s.TimersOutstanding)
+	e.Counter(n("tickers_created_total"), "Tickers created.", s.TickersCreated)
+	e.Gauge(n("tickers_outstanding"), "Tickers not stopped.", s.TickersOutstanding)
+	e.Counter(n("afterfuncs_created_total"), "AfterFunc calls.", s.AfterFuncsCreated)
+	e.Counter(n("afterfuncs_fired_total"), "AfterFunc callbacks run.", s.AfterFuncsFired)
+	e.Counter(n("afterfuncs_cancelled_total"), "AfterFuncs cancelled before running.", s.AfterFuncsCancelled)
+	e.Gauge(n("afterfuncs_pending"), "AfterFuncs neither run nor cancelled.", s.AfterFuncsPending)
+	e.Histogram(n("sleep_overshoot_seconds"), "Sleep duration beyond the requested one.", s.SleepOvershoot)
+	e.Histogram(n("timer_overshoot_seconds"), "Timer fire delay beyond the requested duration.", s.TimerOvershoot)
+	e.Histogram(n("afterfunc_overshoot_seconds"), "AfterFunc run delay beyond the requested duration.", s.AfterFuncOvershoot)
+}
+
+// Handler returns an http.Handler serving the metrics in Prometheus text format.
+func (c *Clock) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		bw := bufio.NewWriter(w)
+		c.Export(&promWriter{w: bw})
+		bw.Flush()
+	})
+}
+
+// promWriter renders the Prometheus text exposition format.
+type promWriter struct {
+	w *bufio.Writer
+}
+
+func (p *promWriter) header(name, help, kind string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *promWriter) Counter(name, help string, v uint64) {
+	p.header(name, help, "counter")
+	fmt.Fprintf(p.w, "%s %d\n", name, v)
+}
+
+func (p *promWriter) Gauge(name, help string, v int64) {
+	p.header(name, help, "gauge")
+	fmt.Fprintf(p.w, "%s %d\n", name, v)
+}
+
+func (p *promWriter) Histogram(name, help string, h HistogramSnapshot) {
+	p.header(name, help, "histogram")
+	var cum uint64
+	for i, b := range h.Bounds {
+		cum += h.Counts[i]
+		fmt.Fprintf(p.w, "%s_bucket{le=%q} %d\n", name, seconds(b), cum)
+	}
+	cum += h.Counts[len(h.Bounds)]
+	fmt.Fprintf(p.w, "%s_bucket{le=\"+Inf\"} %d\n", name, cum)
+	// _count must equal the +Inf bucket.
+	fmt.Fprintf(p.w, "%s_sum %s\n%s_count %d\n", name, seconds(h.Sum), name, cum)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// Publish exposes the metrics under name in expvar (/debug/vars), as a map of
+// metric name to value; histograms appear as {"buckets", "sum", "count"} with
+// durations in seconds. Like expvar.Publish, it panics if name is already used.
+func (c *Clock) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		m := mapExporter{}
+		c.Export(m)
+		return map[string]any(m)
+	}))
+}
+
+type mapExporter map[string]any
+
+func (m mapExporter) Counter(name, _ string, v uint64) { m[name] = v }
+func (m mapExporter) Gauge(name, _ string, v int64)    { m[name] = v }
+func (m mapExporter) Histogram(name, _ string, h HistogramSnapshot) {
+	buckets := make(map[string]uint64, len(h.Counts))
+	var cum uint64
+	for i, b := range h.Bounds {
+		cum += h.Counts[i]
+		buckets[seconds(b)] = cum
+	}
+	buckets["+Inf"] = cum + h.Counts[len(h.Bounds)]
+	m[name] = map[string]any{"buckets": buckets, "sum": h.Sum.Seconds(), "count": h.Count}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBuckets are the overshoot histogram upper bounds: 1µs to 1s.
+var DefaultBuckets = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Histogram is a lock-free fixed-bucket duration histogram.
+type Histogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64 // len(bounds)+1; last is +Inf
+	sum    atomic.Int64
+}
+
+func newHistogram(bounds []time.Duration) *Histogram {
+	return &Histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// Observe records d; negative values count as 0.
+func (h *Histogram) Observe(d time.Duration) {
+	d = max(d, 0)
+	i := 0
+	for i < len(h.bounds) && d > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+// HistogramSnapshot is a point-in-time copy of a Histogram.
+type HistogramSnapshot struct {
+	// Bounds are the bucket upper bounds; the implicit last bucket is +Inf.
+	Bounds []time.Duration
+	// Counts are per-bucket (non-cumulative) counts, len(Bounds)+1.
+	Counts []uint64
+	Sum    time.Duration
+	Count  uint64
+}
+
+// Snapshot copies the current state. Count is the total of the copied buckets,
+// so it always matches them; Sum is read separately and may briefly include an
+// observation the buckets do not, or miss one they do.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Bounds: slices.Clone(h.bounds),
+		Counts: make([]uint64, len(h.counts)),
+		Sum:    time.Duration(h.sum.Load()),
+	}
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+		s.Count += s.Counts[i]
+	}
+	return s
+}
+
+// Mean returns Sum/Count, or 0 if empty.
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
@@ -0,0 +1,135 @@
+package monotonic
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Monotonic clock: a guard layer whose Now() never goes backwards, whatever the
+// base does (jitter, offset steps, calibration resyncs). A regression is clamped
+// to the last returned value and counted.
+//
+// Notes:
+// - The last value is advanced with a CAS loop; safe for concurrent use.
+// - Strict mode adds 1ns on ties so every call returns a distinct, strictly
+//   increasing time. Under a burst of calls it may run a few ns ahead of the base.
+// - Returned times carry no monotonic reading, so comparisons and Sub use the
+//   wall value the guard orders. The base's location is preserved.
+// - Timers, tickers and sleeps delegate to the base unchanged.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Strict makes Now() strictly increasing by adding 1ns to ties.
+	Strict bool
+}
+
+// Set sets the monotonic clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := monotonic.Set(monotonic.Config{Base: jitter.New(nil, time.Millisecond)})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the monotonic clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the monotonic clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+type Clock struct {
+	base   xclock.Clock
+	strict bool
+
+	last          atomic.Int64 // UnixNano of the last returned value
+	clamps        atomic.Uint64
+	maxRegression atomic.Int64
+}
+
+// New constructs a monotonic guard.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	c := &Clock{base: cfg.Base, strict: cfg.Strict}
+	c.last.Store(minInt64)
+	return c
+}
+
+const minInt64 = -1 << 63
+
+// Now returns the base time, or the last returned time (+1ns in strict mode) if
+// the base went backwards.
+func (c *Clock) Now() time.Time {
+	t := c.base.Now().Round(0)
+	n := t.UnixNano()
+	for {
+		prev := c.last.Load()
+		if n > prev || (n == prev && !c.strict) {
+			if c.last.CompareAndSwap(prev, n) {
+				return t
+			}
+			continue
+		}
+		want := prev
+		if c.strict {
+			want++
+		}
+		if c.last.CompareAndSwap(prev, want) {
+			// Ties in strict mode are not regressions.
+			if n < prev {
+				c.clamps.Add(1)
+				c.noteRegression(prev - n)
+			}
+			return time.Unix(0, want).In(t.Location())
+		}
+	}
+}
+
+func (c *Clock) noteRegression(d int64) {
+	for {
+		cur := c.maxRegression.Load()
+		if d <= cur || c.maxRegression.CompareAndSwap(cur, d) {
+			return
+		}
+	}
+}
+
+// Clamps returns how many times Now() had to hide a backwards base reading.
+func (c *Clock) Clamps() uint64 { return c.clamps.Load() }
+
+// MaxRegression returns the largest backwards step of the base seen so far.
+func (c *Clock) MaxRegression() time.Duration { return time.Duration(c.maxRegression.Load()) }
+
+// Last returns the last value returned by Now(), or the zero time before the first call.
+func (c *Clock) Last() time.Time {
+	n := c.last.Load()
+	if n == minInt64 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+func (c *Clock) Since(t time.Time) time.Duration        { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration)                  { c.base.Sleep(d) }
+func (c *Clock) After(d time.Duration) <-chan time.Time { return c.base.After(d) }
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(d, f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
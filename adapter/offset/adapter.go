@@ -13,6 +13,7 @@ import (
 // Notes:
 // - Only Now/Since use the offset; Sleep/After/Timers/Tickers are delegated.
 // - The offset can be adjusted at runtime via SetOffset/AdjustOffset.
+// - NewTimerAt/AfterFuncAt target wall instants of Now() and re-arm on offset changes.
 
 type Config struct {
 	// Base is the underlying clock to wrap. If nil, xclock.Default() is used.
@@ -64,6 +65,7 @@ func New(base xclock.Clock, d time.Duration) xclock.Clock {
 type clock struct {
 	base   xclock.Clock
 	offset atomic.Int64 // nanoseconds
+	wall   xclock.WallTimers
 }
 
 func (o *clock) Now() time.Time {
@@ -82,11 +84,22 @@ func (o *clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
 func (o *clock) NewTimer(d time.Duration) xclock.Timer   { return o.base.NewTimer(d) }
 func (o *clock) NewTicker(d time.Duration) xclock.Ticker { return o.base.NewTicker(d) }
 
+func (o *clock) NewTimerAt(t time.Time) xclock.Timer { return o.wall.NewTimerAt(o, t) }
+func (o *clock) AfterFuncAt(t time.Time, f func()) xclock.CancelFunc {
+	return o.wall.AfterFuncAt(o, t, f)
+}
+
 // SetOffset sets the absolute offset applied to base time.
-func (o *clock) SetOffset(d time.Duration) { o.offset.Store(int64(d)) }
+func (o *clock) SetOffset(d time.Duration) {
+	o.offset.Store(int64(d))
+	o.wall.Rearm()
+}
 
 // AdjustOffset adds d to the current offset (can be negative).
-func (o *clock) AdjustOffset(d time.Duration) { o.offset.Add(int64(d)) }
+func (o *clock) AdjustOffset(d time.Duration) {
+	o.offset.Add(int64(d))
+	o.wall.Rearm()
+}
 
 // Offset returns the current configured offset.
 func (o *clock) Offset() time.Duration { return time.Duration(o.offset.Load()) }
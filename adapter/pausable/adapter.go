@@ -0,0 +1,170 @@
+package pausable
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Pausable clock: Now() stops while paused and continues from the same instant on
+// Resume, without the jump that swapping in a frozen clock and back would cause.
+//
+// Notes:
+// - Time spent paused accumulates as an offset: Now() = base.Now() - PausedTotal().
+// - Pending timers, tickers, AfterFuncs and sleeps hold their remaining duration
+//   while paused and resume with it; they are wall deadlines in pausable time.
+// - Pause/Resume are idempotent and safe for concurrent use.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Paused starts the clock paused.
+	Paused bool
+}
+
+// Set sets the pausable clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := pausable.Set(pausable.Config{})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the pausable clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the pausable clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+type state struct {
+	paused bool
+	at     time.Time     // base time when paused
+	offset time.Duration // paused time accumulated before the current pause
+}
+
+type Clock struct {
+	base  xclock.Clock
+	state atomic.Pointer[state]
+	mu    sync.Mutex // serializes Pause/Resume
+	wall  xclock.WallTimers
+	raw   rawClock
+}
+
+// New constructs a pausable clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	c := &Clock{base: cfg.Base}
+	c.raw = rawClock{c}
+	s := &state{}
+	if cfg.Paused {
+		s.paused = true
+		s.at = cfg.Base.Now()
+	}
+	c.state.Store(s)
+	return c
+}
+
+// Now returns base time minus all paused time; it stands still while paused.
+func (c *Clock) Now() time.Time {
+	s := c.state.Load()
+	if s.paused {
+		return s.at.Add(-s.offset)
+	}
+	return c.base.Now().Add(-s.offset)
+}
+
+// Pause stops time. Pending timers keep their remaining duration.
+func (c *Clock) Pause() {
+	c.mu.Lock()
+	s := c.state.Load()
+	if s.paused {
+		c.mu.Unlock()
+		return
+	}
+	c.state.Store(&state{paused: true, at: c.base.Now(), offset: s.offset})
+	c.mu.Unlock()
+	c.wall.Rearm() // disarms base timers; see rawClock.AfterFunc
+}
+
+// Resume restarts time from where it was paused.
+func (c *Clock) Resume() {
+	c.mu.Lock()
+	s := c.state.Load()
+	if !s.paused {
+		c.mu.Unlock()
+		return
+	}
+	c.state.Store(&state{offset: s.offset + c.base.Now().Sub(s.at)})
+	c.mu.Unlock()
+	c.wall.Rearm()
+}
+
+// Paused reports whether the clock is paused.
+func (c *Clock) Paused() bool { return c.state.Load().paused }
+
+// PausedTotal returns the accumulated paused time, including the current pause.
+func (c *Clock) PausedTotal() time.Duration {
+	s := c.state.Load()
+	if s.paused {
+		return s.offset + c.base.Now().Sub(s.at)
+	}
+	return s.offset
+}
+
+func (c *Clock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-c.After(d)
+}
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.wall.AfterFuncAt(c.raw, c.Now().Add(d), f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	return c.wall.NewTimerAt(c.raw, c.Now().Add(d))
+}
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.wall.NewTicker(c.raw, d) }
+
+func (c *Clock) NewTimerAt(t time.Time) xclock.Timer { return c.wall.NewTimerAt(c.raw, t) }
+func (c *Clock) AfterFuncAt(t time.Time, f func()) xclock.CancelFunc {
+	return c.wall.AfterFuncAt(c.raw, t, f)
+}
+
+// rawClock arms WallTimers on the base clock while running and not at all while
+// paused; Pause and Resume call Rearm so every deadline is re-evaluated.
+type rawClock struct{ c *Clock }
+
+func (r rawClock) Now() time.Time                  { return r.c.Now() }
+func (r rawClock) Since(t time.Time) time.Duration { return r.c.Since(t) }
+func (r rawClock) Sleep(d time.Duration)           { r.c.base.Sleep(d) }
+func (r rawClock) After(d time.Duration) <-chan time.Time {
+	return r.c.base.After(d)
+}
+func (r rawClock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	if r.c.Paused() {
+		return func() bool { return false }
+	}
+	return r.c.base.AfterFunc(d, f)
+}
+func (r rawClock) NewTimer(d time.Duration) xclock.Timer   { return r.c.base.NewTimer(d) }
+func (r rawClock) NewTicker(d time.Duration) xclock.Ticker { return r.c.base.NewTicker(d) }
@@ -0,0 +1,206 @@
+package precise
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Precise clock: hybrid sleep for sub-millisecond waits. Sleep(d) sleeps on the
+// base clock until Margin before the deadline, then spins (or yields) on the
+// monotonic clock until the deadline passes.
+//
+// Notes:
+// - Only Sleep is precise; Now/After/Timers/Tickers delegate to the base clock.
+// - Spinning measures real monotonic time, so use it over real-time bases
+//   (system, offset, calibrated), not frozen or virtual ones.
+// - SpinBudget caps the fraction of wall time spent spinning across all sleeps;
+//   over budget, Sleep falls back to a plain base sleep.
+// - Stats reports achieved overshoot so callers can tune Margin.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Margin is how long before the deadline to stop sleeping and start spinning.
+	// Defaults to 1ms; it also bounds the spin time of a single Sleep.
+	Margin time.Duration
+	// Yield calls runtime.Gosched between checks instead of busy-spinning.
+	Yield bool
+	// SpinBudget is the maximum fraction of wall time (0, 1] spent spinning.
+	// 0 means unlimited.
+	SpinBudget float64
+}
+
+// Set sets the precise clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := precise.Set(precise.Config{Margin: 500 * time.Microsecond})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the precise clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the precise clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// Stats reports achieved sleep accuracy.
+type Stats struct {
+	// Sleeps counts Sleep calls with d > 0.
+	Sleeps uint64
+	// Spins counts sleeps that finished by spinning.
+	Spins uint64
+	// BudgetSkips counts sleeps that did not spin because SpinBudget was exhausted.
+	BudgetSkips uint64
+	// SpinTime is the total time spent spinning.
+	SpinTime time.Duration
+	// TotalOvershoot and MaxOvershoot measure actual minus requested duration.
+	TotalOvershoot time.Duration
+	MaxOvershoot   time.Duration
+}
+
+// MeanOvershoot returns TotalOvershoot / Sleeps.
+func (s Stats) MeanOvershoot() time.Duration {
+	if s.Sleeps == 0 {
+		return 0
+	}
+	return s.TotalOvershoot / time.Duration(s.Sleeps)
+}
+
+type Clock struct {
+	base    xclock.Clock
+	margin  time.Duration
+	yield   bool
+	budget  float64
+	created time.Time
+
+	sleeps    atomic.Uint64
+	spins     atomic.Uint64
+	skips     atomic.Uint64
+	spinTime  atomic.Int64
+	overshoot atomic.Int64
+	maxOver   atomic.Int64
+}
+
+// New constructs a precise clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Margin <= 0 {
+		cfg.Margin = time.Millisecond
+	}
+	if cfg.SpinBudget < 0 || cfg.SpinBudget > 1 {
+		cfg.SpinBudget = 0
+	}
+	return &Clock{
+		base:    cfg.Base,
+		margin:  cfg.Margin,
+		yield:   cfg.Yield,
+		budget:  cfg.SpinBudget,
+		created: time.Now(),
+	}
+}
+
+var defaultPrecise = New(Config{Base: xclock.System()})
+
+// Sleep is a precise sleep on the system clock with default settings.
+func Sleep(d time.Duration) { defaultPrecise.Sleep(d) }
+
+// Sleep blocks for at least d, aiming to overshoot by as little as possible.
+func (c *Clock) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	start := time.Now()
+	deadline := start.Add(d)
+
+	spin := c.withinBudget(start)
+	if !spin {
+		c.skips.Add(1)
+		c.base.Sleep(d)
+		c.record(start, d)
+		return
+	}
+
+	if coarse := d - c.margin; coarse > 0 {
+		c.base.Sleep(coarse)
+	}
+	spinStart := time.Now()
+	for time.Now().Before(deadline) {
+		if c.yield {
+			runtime.Gosched()
+		}
+	}
+	c.spins.Add(1)
+	c.spinTime.Add(int64(time.Since(spinStart)))
+	c.record(start, d)
+}
+
+// withinBudget reports whether spinning keeps total spin time within SpinBudget.
+func (c *Clock) withinBudget(now time.Time) bool {
+	if c.budget == 0 {
+		return true
+	}
+	allowed := time.Duration(c.budget * float64(now.Sub(c.created)))
+	return time.Duration(c.spinTime.Load())+c.margin <= allowed
+}
+
+func (c *Clock) record(start time.Time, d time.Duration) {
+	over := time.Since(start) - d
+	c.sleeps.Add(1)
+	c.overshoot.Add(int64(over))
+	for {
+		m := c.maxOver.Load()
+		if int64(over) <= m || c.maxOver.CompareAndSwap(m, int64(over)) {
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of achieved accuracy.
+func (c *Clock) Stats() Stats {
+	return Stats{
+		Sleeps:         c.sleeps.Load(),
+		Spins:          c.spins.Load(),
+		BudgetSkips:    c.skips.Load(),
+		SpinTime:       time.Duration(c.spinTime.Load()),
+		TotalOvershoot: time.Duration(c.overshoot.Load()),
+		MaxOvershoot:   time.Duration(c.maxOver.Load()),
+	}
+}
+
+// ResetStats clears the accuracy counters (the spin budget keeps its history).
+func (c *Clock) ResetStats() {
+	c.sleeps.Store(0)
+	c.spins.Store(0)
+	c.skips.Store(0)
+	c.overshoot.Store(0)
+	c.maxOver.Store(0)
+}
+
+func (c *Clock) Now() time.Time                  { return c.base.Now() }
+func (c *Clock) Since(t time.Time) time.Duration { return c.base.Since(t) }
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.base.After(d)
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(d, f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
@@ -0,0 +1,263 @@
+package record
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Record clock: logs every Clock call (method, arguments, returned time) and every
+// timer, ticker and AfterFunc firing to an io.Writer, as JSONL or compact binary.
+// Feed the recording to adapter/replay to reproduce the same timing sequence.
+//
+// Notes:
+// - Events are written synchronously under a mutex: calls as they are made,
+//   firings just before they are delivered. Wrap W in a bufio.Writer for
+//   throughput and Flush it before exit.
+// - Write errors never disturb the clock; the first one is kept (see Err) and
+//   recording stops.
+// - Timers and AfterFuncs are built on base.AfterFunc and tickers on
+//   base.NewTicker, so each firing can be logged; a tick dropped for a slow
+//   receiver is not logged.
+// - Times are stored without monotonic readings; binary recordings store UTC.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// W receives the recording. If nil, events are discarded.
+	W io.Writer
+	// Format is the encoding. Defaults to JSONL.
+	Format Format
+}
+
+// Set sets the recording clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := record.Set(record.Config{W: f, Format: record.Binary})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the recording clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the recording clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+type Clock struct {
+	base xclock.Clock
+	ids  atomic.Uint64
+
+	mu    sync.Mutex
+	enc   *Encoder
+	err   error
+	count uint64
+}
+
+// New constructs a recording clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.W == nil {
+		cfg.W = io.Discard
+	}
+	return &Clock{base: cfg.Base, enc: NewEncoder(cfg.W, cfg.Format)}
+}
+
+// Err returns the first write error, if any.
+func (c *Clock) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Count returns the number of events written.
+func (c *Clock) Count() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+func (c *Clock) emit(ev Event) {
+	ev.T = ev.T.Round(0)
+	c.mu.Lock()
+	if c.err == nil {
+		if c.err = c.enc.Encode(ev); c.err == nil {
+			c.count++
+		}
+	}
+	c.mu.Unlock()
+}
+
+func (c *Clock) Now() time.Time {
+	t := c.base.Now()
+	c.emit(Event{Op: OpNow, T: t})
+	return t
+}
+
+func (c *Clock) Since(t time.Time) time.Duration {
+	now := c.base.Now()
+	c.emit(Event{Op: OpSince, T: now})
+	return now.Sub(t)
+}
+
+func (c *Clock) Sleep(d time.Duration) {
+	c.emit(Event{Op: OpSleep, D: d})
+	c.base.Sleep(d)
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	t := &timer{c: c, id: c.ids.Add(1), ch: make(chan time.Time, 1)}
+	c.emit(Event{Op: OpAfter, ID: t.id, D: d})
+	t.arm(d)
+	return t.ch
+}
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	id := c.ids.Add(1)
+	c.emit(Event{Op: OpAfterFunc, ID: id, D: d})
+	cancel := c.base.AfterFunc(d, func() {
+		c.emit(Event{Op: OpFire, ID: id, T: c.base.Now()})
+		f()
+	})
+	return func() bool {
+		ok := cancel()
+		c.emit(Event{Op: OpCancel, ID: id, OK: ok})
+		return ok
+	}
+}
+
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	t := &timer{c: c, id: c.ids.Add(1), ch: make(chan time.Time, 1)}
+	c.emit(Event{Op: OpNewTimer, ID: t.id, D: d})
+	t.arm(d)
+	return t
+}
+
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	t := &ticker{
+		c:    c,
+		id:   c.ids.Add(1),
+		t:    c.base.NewTicker(d),
+		ch:   make(chan time.Time, 1),
+		done: make(chan struct{}),
+	}
+	c.emit(Event{Op: OpNewTicker, ID: t.id, D: d})
+	go t.run()
+	return t
+}
+
+// timer is a one-shot timer on base.AfterFunc, so the firing can be recorded.
+type timer struct {
+	c  *Clock
+	id uint64
+	ch chan time.Time
+
+	mu     sync.Mutex
+	gen    uint64
+	cancel xclock.CancelFunc
+}
+
+func (t *timer) arm(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gen++
+	gen := t.gen
+	t.cancel = t.c.base.AfterFunc(d, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if gen != t.gen {
+			return
+		}
+		t.cancel = nil
+		now := t.c.base.Now()
+		t.c.emit(Event{Op: OpFire, ID: t.id, T: now})
+		select {
+		case t.ch <- now:
+		default:
+		}
+	})
+}
+
+// stop disarms the timer and reports whether it was pending.
+func (t *timer) stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gen++
+	if t.cancel == nil {
+		return false
+	}
+	ok := t.cancel()
+	t.cancel = nil
+	return ok
+}
+
+func (t *timer) C() <-chan time.Time { return t.ch }
+
+func (t *timer) Stop() bool {
+	ok := t.stop()
+	t.c.emit(Event{Op: OpTimerStop, ID: t.id, OK: ok})
+	return ok
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	ok := t.stop()
+	t.c.emit(Event{Op: OpTimerReset, ID: t.id, D: d, OK: ok})
+	t.arm(d)
+	return ok
+}
+
+// ticker relays base ticks and records each one delivered.
+type ticker struct {
+	c    *Clock
+	id   uint64
+	t    xclock.Ticker
+	ch   chan time.Time
+	done chan struct{}
+	once sync.Once
+}
+
+func (t *ticker) C() <-chan time.Time { return t.ch }
+
+func (t *ticker) Stop() {
+	t.t.Stop()
+	t.once.Do(func() { close(t.done) })
+	t.c.emit(Event{Op: OpTickerStop, ID: t.id})
+}
+
+func (t *ticker) Reset(d time.Duration) {
+	t.c.emit(Event{Op: OpTickerReset, ID: t.id, D: d})
+	t.t.Reset(d)
+}
+
+func (t *ticker) run() {
+	for {
+		select {
+		case at := <-t.t.C():
+			// Record before delivering so the fire precedes whatever the receiver
+			// does next. Only run sends on ch, so a free slot stays free.
+			if len(t.ch) == 0 {
+				t.c.emit(Event{Op: OpFire, ID: t.id, T: at})
+				t.ch <- at
+			}
+		case <-t.done:
+			return
+		}
+	}
+}
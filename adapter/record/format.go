@@ -0,0 +1,237 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Op identifies a recorded Clock call or timer event.
+type Op uint8
+
+const (
+	OpNow         Op = iota + 1
+	OpSince          // T: the Now() the result was computed from
+	OpSleep          // D: requested duration
+	OpAfter          // ID, D
+	OpAfterFunc      // ID, D
+	OpCancel         // ID; OK: CancelFunc result
+	OpNewTimer       // ID, D
+	OpTimerStop      // ID; OK: Stop result
+	OpTimerReset     // ID, D; OK: Reset result
+	OpNewTicker      // ID, D
+	OpTickerStop     // ID
+	OpTickerReset    // ID, D
+	OpFire           // ID; T: fire time. Not a call: timers, tickers and AfterFuncs firing.
+)
+
+var opNames = [...]string{
+	OpNow:         "now",
+	OpSince:       "since",
+	OpSleep:       "sleep",
+	OpAfter:       "after",
+	OpAfterFunc:   "afterfunc",
+	OpCancel:      "cancel",
+	OpNewTimer:    "timer",
+	OpTimerStop:   "timer.stop",
+	OpTimerReset:  "timer.reset",
+	OpNewTicker:   "ticker",
+	OpTickerStop:  "ticker.stop",
+	OpTickerReset: "ticker.reset",
+	OpFire:        "fire",
+}
+
+func (o Op) String() string {
+	if int(o) < len(opNames) && opNames[o] != "" {
+		return opNames[o]
+	}
+	return fmt.Sprintf("Op(%d)", uint8(o))
+}
+
+func (o Op) MarshalText() ([]byte, error) {
+	if int(o) >= len(opNames) || opNames[o] == "" {
+		return nil, fmt.Errorf("record: unknown op %d", uint8(o))
+	}
+	return []byte(opNames[o]), nil
+}
+
+func (o *Op) UnmarshalText(b []byte) error {
+	for i, n := range opNames {
+		if n != "" && n == string(b) {
+			*o = Op(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("record: unknown op %q", b)
+}
+
+// Event is one entry of a recording, in call order.
+type Event struct {
+	Op Op            `json:"op"`
+	ID uint64        `json:"id,omitempty"` // timer, ticker or AfterFunc identity
+	D  time.Duration `json:"d,omitempty"`  // duration argument
+	T  time.Time     `json:"t,omitzero"`   // returned or fire time
+	OK bool          `json:"ok,omitempty"` // Stop/Reset/Cancel result
+}
+
+// Format selects the encoding of a recording.
+type Format int
+
+const (
+	// JSONL writes one JSON object per line; readable and diffable.
+	JSONL Format = iota
+	// Binary writes compact varint records after a magic header.
+	Binary
+)
+
+// binaryMagic starts every binary recording; Decoder uses it to detect the format.
+const binaryMagic = "XCLKREC1"
+
+const (
+	flagOK   = 1 << 0
+	flagTime = 1 << 1
+)
+
+// Encoder writes events to an io.Writer. It is not safe for concurrent use.
+type Encoder struct {
+	w      io.Writer
+	format Format
+	header bool
+	buf    []byte
+}
+
+// NewEncoder returns an encoder writing format to w.
+func NewEncoder(w io.Writer, format Format) *Encoder {
+	return &Encoder{w: w, format: format}
+}
+
+// Encode writes one event.
+func (e *Encoder) Encode(ev Event) error {
+	b := e.buf[:0]
+	if e.format == Binary {
+		if !e.header {
+			b = append(b, binaryMagic...)
+			e.header = true
+		}
+		var flags byte
+		if ev.OK {
+			flags |= flagOK
+		}
+		if !ev.T.IsZero() {
+			flags |= flagTime
+		}
+		b = append(b, byte(ev.Op), flags)
+		b = binary.AppendUvarint(b, ev.ID)
+		b = binary.AppendVarint(b, int64(ev.D))
+		if !ev.T.IsZero() {
+			b = binary.AppendVarint(b, ev.T.UnixNano())
+		}
+	} else {
+		j, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		b = append(append(b, j...), '\n')
+	}
+	e.buf = b
+	_, err := e.w.Write(b)
+	return err
+}
+
+// Decoder reads events written by an Encoder in either format.
+type Decoder struct {
+	r      *bufio.Reader
+	format Format
+	probed bool
+}
+
+// NewDecoder returns a decoder reading from r. The format is detected from the
+// first bytes.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next event. It returns io.EOF at the end of the stream.
+func (d *Decoder) Decode() (Event, error) {
+	if !d.probed {
+		d.probed = true
+		if p, _ := d.r.Peek(len(binaryMagic)); string(p) == binaryMagic {
+			d.format = Binary
+			d.r.Discard(len(binaryMagic))
+		}
+	}
+	if d.format == Binary {
+		return d.decodeBinary()
+	}
+	for {
+		line, err := d.r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return Event{}, err
+			}
+			continue
+		}
+		var ev Event
+		if jerr := json.Unmarshal(line, &ev); jerr != nil {
+			return Event{}, fmt.Errorf("record: %w", jerr)
+		}
+		return ev, nil
+	}
+}
+
+func (d *Decoder) decodeBinary() (Event, error) {
+	op, err := d.r.ReadByte()
+	if err != nil {
+		return Event{}, err
+	}
+	flags, err := d.r.ReadByte()
+	if err != nil {
+		return Event{}, truncated(err)
+	}
+	ev := Event{Op: Op(op), OK: flags&flagOK != 0}
+	if ev.ID, err = binary.ReadUvarint(d.r); err != nil {
+		return Event{}, truncated(err)
+	}
+	dur, err := binary.ReadVarint(d.r)
+	if err != nil {
+		return Event{}, truncated(err)
+	}
+	ev.D = time.Duration(dur)
+	if flags&flagTime != 0 {
+		ns, err := binary.ReadVarint(d.r)
+		if err != nil {
+			return Event{}, truncated(err)
+		}
+		ev.T = time.Unix(0, ns).UTC()
+	}
+	return ev, nil
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ReadAll decodes every event from r.
+func ReadAll(r io.Reader) ([]Event, error) {
+	var out []Event
+	d := NewDecoder(r)
+	for {
+		ev, err := d.Decode()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, ev)
+	}
+}
@@ -0,0 +1,439 @@
+package replay
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/adapter/record"
+)
+
+// Replay clock: plays back a recording made with adapter/record. Now() and Since()
+// return the recorded values in order, sleeps return immediately, and timers,
+// tickers and AfterFuncs fire at the recorded points of the sequence with the
+// recorded times. Calls that do not match the recording are reported as
+// divergences.
+//
+// Notes:
+// - Each call is matched against the next unconsumed recorded call of the same
+//   method (and, for timer methods, the same timer) within Window events, so
+//   goroutine interleaving that differs slightly from the recording is tolerated.
+// - A matched call with different arguments is consumed and reported
+//   (ArgMismatch). A call with no match within the window is reported
+//   (Unexpected) and served from the last replayed time.
+// - A recorded firing is delivered once every event before it has been
+//   consumed; firings of timers the program never created are dropped.
+// - Strict panics on the first divergence instead of continuing.
+
+// Kind classifies a Divergence.
+type Kind int
+
+const (
+	// Unexpected is a call with no matching recorded call.
+	Unexpected Kind = iota
+	// ArgMismatch is a call matching a recorded call except for its arguments.
+	ArgMismatch
+)
+
+// Divergence describes a call that did not match the recording.
+type Divergence struct {
+	Kind  Kind
+	Call  int           // 1-based index of the call in the replayed program
+	Op    record.Op     // method called
+	ID    uint64        // recorded timer identity, if known
+	D     time.Duration // duration argument
+	Want  *record.Event // recorded event consumed or expected next; nil at end of recording
+	Index int           // index of Want in the recording, or -1
+}
+
+func (d Divergence) Error() string {
+	var want string
+	if d.Want == nil {
+		want = "end of recording"
+	} else {
+		want = fmt.Sprintf("%s(%v) at event %d", d.Want.Op, d.Want.D, d.Index)
+	}
+	kind := "unexpected"
+	if d.Kind == ArgMismatch {
+		kind = "argument mismatch"
+	}
+	return fmt.Sprintf("replay: call %d %s(%v): %s; recording has %s", d.Call, d.Op, d.D, kind, want)
+}
+
+type Config struct {
+	// Events is the recording, e.g. from record.ReadAll.
+	Events []record.Event
+	// Window bounds how far ahead a call is matched. Defaults to 64.
+	Window int
+	// OnDivergence, if set, is called for every divergence, outside internal locks.
+	OnDivergence func(Divergence)
+	// Strict panics with the Divergence instead of continuing.
+	Strict bool
+}
+
+// Set sets the replay clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	events, err := record.ReadAll(f)
+//	// handle err
+//	restore := replay.Set(replay.Config{Events: events, Strict: true})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the replay clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the replay clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// unbound is the identity of timers whose creation did not match the recording;
+// it matches no recorded event.
+const unbound = ^uint64(0)
+
+// target receives recorded firings for one timer, ticker or AfterFunc.
+type target interface {
+	fire(t time.Time)
+}
+
+type Clock struct {
+	window int
+	onDiv  func(Divergence)
+	strict bool
+
+	mu     sync.Mutex
+	events []record.Event
+	used   []bool
+	cursor int // first unconsumed event
+	calls  int
+	last   time.Time
+	live   map[uint64]target // recorded ID -> live object
+	divs   []Divergence
+}
+
+// New constructs a replay clock over cfg.Events.
+func New(cfg Config) *Clock {
+	if cfg.Window <= 0 {
+		cfg.Window = 64
+	}
+	c := &Clock{
+		window: cfg.Window,
+		onDiv:  cfg.OnDivergence,
+		strict: cfg.Strict,
+		events: cfg.Events,
+		used:   make([]bool, len(cfg.Events)),
+		live:   make(map[uint64]target),
+	}
+	// Serve divergent calls before the first recorded time from it.
+	for _, ev := range cfg.Events {
+		if !ev.T.IsZero() {
+			c.last = ev.T
+			break
+		}
+	}
+	return c
+}
+
+// Divergences returns every divergence so far.
+func (c *Clock) Divergences() []Divergence {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Divergence(nil), c.divs...)
+}
+
+// Remaining returns the number of recorded events not yet replayed.
+func (c *Clock) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for i := c.cursor; i < len(c.events); i++ {
+		if !c.used[i] {
+			n++
+		}
+	}
+	return n
+}
+
+// Done reports whether the whole recording has been replayed.
+func (c *Clock) Done() bool { return c.Remaining() == 0 }
+
+// call matches one program call against the recording. id is the recorded
+// identity for calls on an existing timer (0 otherwise); bind, if non-nil, is
+// registered for firings of the matched event's ID. It reports whether the call
+// matched (possibly with an argument mismatch).
+func (c *Clock) call(op record.Op, id uint64, d time.Duration, bind target) (record.Event, bool) {
+	c.mu.Lock()
+	c.calls++
+	match, loose := -1, -1
+	end := min(len(c.events), c.cursor+c.window)
+	for i := c.cursor; i < end; i++ {
+		ev := c.events[i]
+		if c.used[i] || ev.Op != op || (id != 0 && ev.ID != id) {
+			continue
+		}
+		if ev.D == d {
+			match = i
+			break
+		}
+		if loose < 0 {
+			loose = i
+		}
+	}
+	var div *Divergence
+	if match < 0 && loose >= 0 {
+		match = loose
+		div = &Divergence{Kind: ArgMismatch, Index: match}
+	}
+	if match < 0 {
+		div = &Divergence{Kind: Unexpected, Index: -1}
+		if next := c.nextCallLocked(); next >= 0 {
+			div.Index = next
+		}
+	}
+	var (
+		ev    record.Event
+		fires []func()
+	)
+	if match >= 0 {
+		ev = c.events[match]
+		c.used[match] = true
+		if !ev.T.IsZero() {
+			c.last = ev.T
+		}
+		if bind != nil {
+			c.live[ev.ID] = bind
+		}
+		fires = c.advanceLocked()
+	}
+	if div != nil {
+		div.Call, div.Op, div.D = c.calls, op, d
+		if id != unbound {
+			div.ID = id
+		}
+		if div.Index >= 0 {
+			w := c.events[div.Index]
+			div.Want = &w
+		}
+		c.divs = append(c.divs, *div)
+	}
+	c.mu.Unlock()
+
+	if div != nil {
+		if c.strict {
+			panic(*div)
+		}
+		if c.onDiv != nil {
+			c.onDiv(*div)
+		}
+	}
+	for _, f := range fires {
+		f()
+	}
+	return ev, match >= 0
+}
+
+// nextCallLocked returns the index of the next unconsumed call event, or -1.
+func (c *Clock) nextCallLocked() int {
+	for i := c.cursor; i < len(c.events); i++ {
+		if !c.used[i] && c.events[i].Op != record.OpFire {
+			return i
+		}
+	}
+	return -1
+}
+
+// advanceLocked moves the cursor past consumed events, consuming the firings it
+// reaches, and returns their deliveries to run outside the lock.
+func (c *Clock) advanceLocked() []func() {
+	var fires []func()
+	for c.cursor < len(c.events) {
+		i := c.cursor
+		if !c.used[i] {
+			ev := c.events[i]
+			if ev.Op != record.OpFire {
+				break
+			}
+			c.used[i] = true
+			c.last = ev.T
+			if t, ok := c.live[ev.ID]; ok {
+				fires = append(fires, func() { t.fire(ev.T) })
+			}
+		}
+		c.cursor++
+	}
+	return fires
+}
+
+func (c *Clock) lastTime() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+func (c *Clock) Now() time.Time {
+	if ev, ok := c.call(record.OpNow, 0, 0, nil); ok {
+		return ev.T
+	}
+	return c.lastTime()
+}
+
+func (c *Clock) Since(t time.Time) time.Duration {
+	if ev, ok := c.call(record.OpSince, 0, 0, nil); ok {
+		return ev.T.Sub(t)
+	}
+	return c.lastTime().Sub(t)
+}
+
+// Sleep returns immediately; time advances as the recording does.
+func (c *Clock) Sleep(d time.Duration) { c.call(record.OpSleep, 0, d, nil) }
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	t := &timer{c: c, id: unbound, ch: make(chan time.Time, 1)}
+	t.bind(c.call(record.OpAfter, 0, d, t))
+	return t.ch
+}
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	a := &afterFunc{f: f}
+	id := uint64(unbound)
+	if ev, ok := c.call(record.OpAfterFunc, 0, d, a); ok {
+		id = ev.ID
+	}
+	return func() bool {
+		r, _ := c.call(record.OpCancel, id, 0, nil)
+		if r.OK {
+			a.cancel()
+		}
+		return r.OK
+	}
+}
+
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	t := &timer{c: c, id: unbound, ch: make(chan time.Time, 1)}
+	t.bind(c.call(record.OpNewTimer, 0, d, t))
+	return t
+}
+
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	t := &ticker{c: c, id: unbound, ch: make(chan time.Time, 1), stopped: make(chan struct{})}
+	if ev, ok := c.call(record.OpNewTicker, 0, d, t); ok {
+		t.id = ev.ID
+	}
+	return t
+}
+
+// timer delivers recorded firings; Stop and Reset return the recorded results.
+type timer struct {
+	c  *Clock
+	id uint64 // unbound if creation diverged
+	ch chan time.Time
+}
+
+func (t *timer) bind(ev record.Event, ok bool) {
+	if ok {
+		t.id = ev.ID
+	}
+}
+
+func (t *timer) fire(at time.Time) {
+	select {
+	case t.ch <- at:
+	default:
+	}
+}
+
+func (t *timer) C() <-chan time.Time { return t.ch }
+
+func (t *timer) Stop() bool {
+	ev, _ := t.c.call(record.OpTimerStop, t.id, 0, nil)
+	return ev.OK
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	ev, _ := t.c.call(record.OpTimerReset, t.id, d, nil)
+	return ev.OK
+}
+
+// ticker queues recorded ticks and delivers them in order as the receiver takes
+// them: the recording only logs ticks that found the channel empty.
+type ticker struct {
+	c       *Clock
+	id      uint64
+	ch      chan time.Time
+	stopped chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	queue   []time.Time
+	pumping bool
+}
+
+func (t *ticker) fire(at time.Time) {
+	t.mu.Lock()
+	t.queue = append(t.queue, at)
+	if !t.pumping {
+		t.pumping = true
+		go t.pump()
+	}
+	t.mu.Unlock()
+}
+
+func (t *ticker) pump() {
+	for {
+		t.mu.Lock()
+		if len(t.queue) == 0 {
+			t.pumping = false
+			t.mu.Unlock()
+			return
+		}
+		at := t.queue[0]
+		t.queue = t.queue[1:]
+		t.mu.Unlock()
+		select {
+		case t.ch <- at:
+		case <-t.stopped:
+			return
+		}
+	}
+}
+
+func (t *ticker) C() <-chan time.Time { return t.ch }
+func (t *ticker) Stop() {
+	t.once.Do(func() { close(t.stopped) })
+	t.c.call(record.OpTickerStop, t.id, 0, nil)
+}
+func (t *ticker) Reset(d time.Duration) { t.c.call(record.OpTickerReset, t.id, d, nil) }
+
+type afterFunc struct {
+	mu       sync.Mutex
+	f        func()
+	canceled bool
+}
+
+func (a *afterFunc) fire(time.Time) {
+	a.mu.Lock()
+	canceled := a.canceled
+	a.mu.Unlock()
+	if !canceled {
+		go a.f()
+	}
+}
+
+func (a *afterFunc) cancel() {
+	a.mu.Lock()
+	a.canceled = true
+	a.mu.Unlock()
+}
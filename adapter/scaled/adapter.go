@@ -0,0 +1,188 @@
+package scaled
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Scaled clock: time runs Factor times faster (or slower) than the base clock.
+// Now() advances Factor× from an anchor; Sleep/After/AfterFunc/Timers/Tickers
+// wait Factor× less on the base clock. For soak tests and simulations that run
+// hours of scheduled behavior in minutes.
+//
+// Notes:
+// - SetFactor re-anchors at the current scaled Now(), so Now() never jumps back,
+//   also for calls racing SetFactor (they retry; Now() is otherwise lock-free).
+// - Pending timers, tickers and sleeps are wall deadlines in scaled time; they
+//   re-arm on SetFactor and fire at the right scaled instant.
+// - Factor must be > 0; use adapter/pausable to stop time.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Factor is the speed-up; 60 runs an hour per real minute. Defaults to 1.
+	Factor float64
+	// Start is the scaled time at construction. If zero, base.Now() is used.
+	Start time.Time
+}
+
+// Set sets the scaled clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	restore := scaled.Set(scaled.Config{Factor: 60})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the scaled clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the scaled clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// anchor pins scaled time to base time: Now = scaled + (base.Now()-base)×factor.
+type anchor struct {
+	base   time.Time
+	scaled time.Time
+	factor float64
+}
+
+type Clock struct {
+	base   xclock.Clock
+	anchor atomic.Pointer[anchor]
+	seq    atomic.Uint64 // odd while SetFactor swaps the anchor
+	mu     sync.Mutex    // serializes SetFactor
+	wall   xclock.WallTimers
+	raw    rawClock
+}
+
+// New constructs a scaled clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Factor <= 0 {
+		cfg.Factor = 1
+	}
+	now := cfg.Base.Now()
+	start := cfg.Start
+	if start.IsZero() {
+		start = now
+	}
+	c := &Clock{base: cfg.Base}
+	c.raw = rawClock{c}
+	c.anchor.Store(&anchor{base: now, scaled: start.Round(0), factor: cfg.Factor})
+	return c
+}
+
+// Now returns the scaled time. A read that overlaps SetFactor is retried: its
+// base time may be later than the new anchor's, and under the old factor it
+// could exceed times returned after the swap.
+func (c *Clock) Now() time.Time {
+	for {
+		seq := c.seq.Load()
+		if seq&1 != 0 {
+			runtime.Gosched()
+			continue
+		}
+		a := c.anchor.Load()
+		now := a.at(c.base.Now())
+		if c.seq.Load() == seq {
+			return now
+		}
+	}
+}
+
+func (a *anchor) at(baseNow time.Time) time.Time {
+	el := baseNow.Sub(a.base)
+	return a.scaled.Add(time.Duration(float64(el) * a.factor))
+}
+
+// Factor returns the current speed-up.
+func (c *Clock) Factor() float64 { return c.anchor.Load().factor }
+
+// SetFactor changes the speed-up from now on. It panics if f <= 0.
+func (c *Clock) SetFactor(f float64) {
+	if f <= 0 {
+		panic("scaled: SetFactor with non-positive factor")
+	}
+	c.mu.Lock()
+	c.seq.Add(1)
+	// Read after seq turns odd: every Now() that completes used an earlier base
+	// time, so none returns more than the new anchor's scaled time.
+	now := c.base.Now()
+	cur := c.anchor.Load()
+	c.anchor.Store(&anchor{base: now, scaled: cur.at(now), factor: f})
+	c.seq.Add(1)
+	c.mu.Unlock()
+	c.wall.Rearm()
+}
+
+// real converts a scaled duration to base duration.
+func (c *Clock) real(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	r := time.Duration(float64(d) / c.anchor.Load().factor)
+	if r <= 0 {
+		r = 1
+	}
+	return r
+}
+
+func (c *Clock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+func (c *Clock) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	<-c.After(d)
+}
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.wall.AfterFuncAt(c.raw, c.Now().Add(d), f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer {
+	return c.wall.NewTimerAt(c.raw, c.Now().Add(d))
+}
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.wall.NewTicker(c.raw, d) }
+
+func (c *Clock) NewTimerAt(t time.Time) xclock.Timer { return c.wall.NewTimerAt(c.raw, t) }
+func (c *Clock) AfterFuncAt(t time.Time, f func()) xclock.CancelFunc {
+	return c.wall.AfterFuncAt(c.raw, t, f)
+}
+
+// rawClock is the scaled clock without deadline tracking: durations are divided
+// by the factor and handed to the base. WallTimers arm through it.
+type rawClock struct{ c *Clock }
+
+func (r rawClock) Now() time.Time                  { return r.c.Now() }
+func (r rawClock) Since(t time.Time) time.Duration { return r.c.Since(t) }
+func (r rawClock) Sleep(d time.Duration)           { r.c.base.Sleep(r.c.real(d)) }
+func (r rawClock) After(d time.Duration) <-chan time.Time {
+	return r.c.base.After(r.c.real(d))
+}
+func (r rawClock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return r.c.base.AfterFunc(r.c.real(d), f)
+}
+func (r rawClock) NewTimer(d time.Duration) xclock.Timer { return r.c.base.NewTimer(r.c.real(d)) }
+func (r rawClock) NewTicker(d time.Duration) xclock.Ticker {
+	return r.c.base.NewTicker(r.c.real(d))
+}
@@ -0,0 +1,184 @@
+package trace
+
+import (
+	"context"
+	rtrace "runtime/trace"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Trace clock: makes time spent waiting on the clock visible. The context-aware
+// methods (SleepContext, AfterContext, WaitContext, Tick) record a span per wait
+// or per tick of work through a minimal Tracer, and every wait is also a
+// runtime/trace region, so it shows up in `go tool trace`.
+//
+// Notes:
+// - The plain Clock methods delegate to the base; Sleep is traced as
+//   SleepContext(context.Background(), d) so untouched call sites still appear
+//   as root spans and regions.
+// - Span names: "xclock.Sleep", "xclock.Wait" and "xclock.Tick"; attributes use
+//   the "xclock." prefix (requested, waited, canceled, tick).
+// - Without a Tracer only runtime/trace regions are emitted; they cost almost
+//   nothing unless a trace is being collected.
+
+type Config struct {
+	// Base is the underlying clock. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Tracer receives spans. If nil, only runtime/trace regions are emitted.
+	Tracer Tracer
+}
+
+// Set sets the trace clock as the process-wide default and returns a restore
+// function that reverts to the previous default when called.
+// Recommended for tests and examples:
+//
+//	tr := trace.NewMemoryTracer(nil)
+//	restore := trace.Set(trace.Config{Tracer: tr})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	xclock.SetDefault(New(cfg))
+	return func() { xclock.SetDefault(prev) }
+}
+
+// Use applies the trace clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with the trace clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// Attribute keys set on spans.
+const (
+	AttrRequested = "xclock.requested" // time.Duration asked for
+	AttrWaited    = "xclock.waited"    // time.Duration actually waited
+	AttrCanceled  = "xclock.canceled"  // bool: the context ended the wait
+	AttrTick      = "xclock.tick"      // time.Time of the tick being processed
+)
+
+type Clock struct {
+	base   xclock.Clock
+	tracer Tracer
+}
+
+// New constructs a trace clock.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Tracer == nil {
+		cfg.Tracer = noopTracer{}
+	}
+	return &Clock{base: cfg.Base, tracer: cfg.Tracer}
+}
+
+// span starts a span and a runtime/trace region; end closes both, recording how
+// long the wait took and whether ctx ended it.
+func (c *Clock) span(ctx context.Context, name string, attrs ...Attr) (end func(canceled bool)) {
+	ctx, sp := c.tracer.Start(ctx, name, attrs...)
+	region := rtrace.StartRegion(ctx, name)
+	start := c.base.Now()
+	return func(canceled bool) {
+		region.End()
+		sp.SetAttributes(Attr{AttrWaited, c.base.Since(start)}, Attr{AttrCanceled, canceled})
+		sp.End()
+	}
+}
+
+// SleepContext sleeps for d or until ctx is done, whichever comes first, and
+// returns ctx.Err() in the latter case.
+func (c *Clock) SleepContext(ctx context.Context, d time.Duration) error {
+	end := c.span(ctx, "xclock.Sleep", Attr{AttrRequested, d})
+	if d <= 0 {
+		end(false)
+		return nil
+	}
+	if ctx.Done() == nil {
+		// Not cancelable: keep the base's own Sleep (e.g. adapter/precise).
+		c.base.Sleep(d)
+		end(false)
+		return nil
+	}
+	t := c.base.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		end(false)
+		return nil
+	case <-ctx.Done():
+		end(true)
+		return ctx.Err()
+	}
+}
+
+// AfterContext waits for d like After, or until ctx is done. It returns the fire
+// time, or ctx.Err().
+func (c *Clock) AfterContext(ctx context.Context, d time.Duration) (time.Time, error) {
+	t := c.base.NewTimer(d)
+	defer t.Stop()
+	return c.wait(ctx, t.C(), Attr{AttrRequested, d})
+}
+
+// WaitContext waits for a value on ch (a timer or ticker channel, or After) or
+// until ctx is done, recording the wait as a span.
+func (c *Clock) WaitContext(ctx context.Context, ch <-chan time.Time) (time.Time, error) {
+	return c.wait(ctx, ch)
+}
+
+func (c *Clock) wait(ctx context.Context, ch <-chan time.Time, attrs ...Attr) (time.Time, error) {
+	end := c.span(ctx, "xclock.Wait", attrs...)
+	select {
+	case at := <-ch:
+		end(false)
+		return at, nil
+	case <-ctx.Done():
+		end(true)
+		return time.Time{}, ctx.Err()
+	}
+}
+
+// Tick runs fn on every tick of a d-period ticker until ctx is done or fn returns
+// an error, which Tick returns. Each wait is a runtime/trace region and each call
+// of fn runs in an "xclock.Tick" span whose context is passed to fn.
+func (c *Clock) Tick(ctx context.Context, d time.Duration, fn func(ctx context.Context, t time.Time) error) error {
+	tk := c.base.NewTicker(d)
+	defer tk.Stop()
+	for {
+		region := rtrace.StartRegion(ctx, "xclock.Wait")
+		var at time.Time
+		select {
+		case at = <-tk.C():
+			region.End()
+		case <-ctx.Done():
+			region.End()
+			return ctx.Err()
+		}
+		tctx, sp := c.tracer.Start(ctx, "xclock.Tick", Attr{AttrRequested, d}, Attr{AttrTick, at})
+		var err error
+		rtrace.WithRegion(tctx, "xclock.Tick", func() { err = fn(tctx, at) })
+		sp.End()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Clock) Now() time.Time                  { return c.base.Now() }
+func (c *Clock) Since(t time.Time) time.Duration { return c.base.Since(t) }
+func (c *Clock) Sleep(d time.Duration)           { _ = c.SleepContext(context.Background(), d) }
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.base.After(d)
+}
+func (c *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	return c.base.AfterFunc(d, f)
+}
+func (c *Clock) NewTimer(d time.Duration) xclock.Timer   { return c.base.NewTimer(d) }
+func (c *Clock) NewTicker(d time.Duration) xclock.Ticker { return c.base.NewTicker(d) }
@@ -0,0 +1,155 @@
+package trace
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Tracer starts spans. It is deliberately small so an OpenTelemetry (or any
+// other) tracer can be adapted in a few lines.
+type Tracer interface {
+	// Start begins a span named name as a child of any span in ctx and returns a
+	// context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span is an in-progress operation.
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	AddEvent(name string, attrs ...Attr)
+	End()
+}
+
+// Attr is a span or event attribute.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// SpanData is a finished span recorded by MemoryTracer.
+type SpanData struct {
+	ID     uint64
+	Parent uint64 // 0 for root spans
+	Name   string
+	Start  time.Time
+	End    time.Time
+	Attrs  []Attr
+	Events []EventData
+}
+
+// Attr returns the value of the last attribute named key.
+func (s SpanData) Attr(key string) (any, bool) {
+	for i := len(s.Attrs) - 1; i >= 0; i-- {
+		if s.Attrs[i].Key == key {
+			return s.Attrs[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// EventData is an event recorded on a span.
+type EventData struct {
+	Name  string
+	Time  time.Time
+	Attrs []Attr
+}
+
+// MemoryTracer records finished spans in memory, for tests.
+type MemoryTracer struct {
+	clock xclock.Clock
+
+	mu    sync.Mutex
+	next  uint64
+	spans []SpanData
+}
+
+// NewMemoryTracer returns a tracer timestamping spans with clk.
+// If clk is nil, xclock.System() is used.
+func NewMemoryTracer(clk xclock.Clock) *MemoryTracer {
+	if clk == nil {
+		clk = xclock.System()
+	}
+	return &MemoryTracer{clock: clk}
+}
+
+type spanKey struct{}
+
+func (m *MemoryTracer) Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	m.mu.Lock()
+	m.next++
+	s := &memSpan{m: m, data: SpanData{ID: m.next, Name: name, Start: m.clock.Now(), Attrs: slices.Clone(attrs)}}
+	m.mu.Unlock()
+	if p, ok := ctx.Value(spanKey{}).(*memSpan); ok && p.m == m {
+		s.data.Parent = p.data.ID
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Spans returns the finished spans in the order they ended.
+func (m *MemoryTracer) Spans() []SpanData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.spans)
+}
+
+// Reset discards recorded spans.
+func (m *MemoryTracer) Reset() {
+	m.mu.Lock()
+	m.spans = nil
+	m.mu.Unlock()
+}
+
+type memSpan struct {
+	m *MemoryTracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *memSpan) SetAttributes(attrs ...Attr) {
+	s.mu.Lock()
+	s.data.Attrs = append(s.data.Attrs, attrs...)
+	s.mu.Unlock()
+}
+
+func (s *memSpan) AddEvent(name string, attrs ...Attr) {
+	now := s.m.clock.Now()
+	s.mu.Lock()
+	s.data.Events = append(s.data.Events, EventData{Name: name, Time: now, Attrs: slices.Clone(attrs)})
+	s.mu.Unlock()
+}
+
+func (s *memSpan) End() {
+	now := s.m.clock.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = now
+	d := s.data
+	s.mu.Unlock()
+
+	s.m.mu.Lock()
+	s.m.spans = append(s.m.spans, d)
+	s.m.mu.Unlock()
+}
+
+// noopTracer is used when Config.Tracer is nil: only runtime/trace regions.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr)    {}
+func (noopSpan) AddEvent(string, ...Attr) {}
+func (noopSpan) End()                     {}
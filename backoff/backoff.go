@@ -0,0 +1,119 @@
+// Package backoff retries operations with backoff delays slept on an xclock.Clock.
+//
+//	err := backoff.Retry(ctx, clk, backoff.Policy{
+//	    Strategy:    backoff.NewExponential(backoff.ExponentialConfig{Initial: 50 * time.Millisecond, Max: 5 * time.Second, Jitter: 0.2, Seed: 42}),
+//	    MaxAttempts: 8,
+//	}, func(ctx context.Context) error {
+//	    return call(ctx)
+//	})
+//
+// Waits go through the supplied clock's timers, so a controllable clock steps
+// retry loops in tests; seeded strategies make the delays reproducible.
+package backoff
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// Policy bundles a Strategy with stop conditions and hooks.
+type Policy struct {
+	// Strategy computes delays. If nil, Constant(100ms) is used.
+	Strategy Strategy
+	// MaxAttempts stops after this many attempts. 0 means unlimited.
+	MaxAttempts int
+	// MaxElapsed stops when the next attempt would start later than this after
+	// the first one. 0 means unlimited.
+	MaxElapsed time.Duration
+	// OnAttempt, if set, is called after every attempt, successful or not.
+	OnAttempt func(a Attempt)
+}
+
+// Attempt describes a finished attempt, passed to Policy.OnAttempt.
+type Attempt struct {
+	// Number is 1-based.
+	Number int
+	// Err is the attempt's result (nil on success).
+	Err error
+	// Elapsed is the time since the first attempt started, per the clock.
+	Elapsed time.Duration
+	// Delay is the wait before the next attempt; 0 when no retry follows.
+	Delay time.Duration
+	// Final reports that no further attempt will be made.
+	Final bool
+}
+
+// Retry calls fn until it succeeds, returns a Permanent error, the policy gives up,
+// or ctx is done. It returns nil on success, the unwrapped error for Permanent
+// failures, ctx.Err() if ctx ends while waiting, and otherwise the last error.
+// A nil clk uses xclock.Default().
+func Retry(ctx context.Context, clk xclock.Clock, p Policy, fn func(ctx context.Context) error) error {
+	if clk == nil {
+		clk = xclock.Default()
+	}
+	if p.Strategy == nil {
+		p.Strategy = Constant(100 * time.Millisecond)
+	}
+	start := clk.Now()
+	var prev time.Duration
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		a := Attempt{Number: attempt, Err: err, Elapsed: clk.Since(start), Final: true}
+
+		var perm *permanentError
+		switch {
+		case err == nil:
+		case errors.As(err, &perm):
+			err = perm.err
+		case ctx.Err() != nil:
+		case p.MaxAttempts > 0 && attempt >= p.MaxAttempts:
+		default:
+			a.Delay = p.Strategy.Delay(attempt, prev)
+			if p.MaxElapsed > 0 && a.Elapsed+a.Delay > p.MaxElapsed {
+				a.Delay = 0
+				break
+			}
+			a.Final = false
+		}
+		if p.OnAttempt != nil {
+			p.OnAttempt(a)
+		}
+		if a.Final {
+			return err
+		}
+
+		if a.Delay > 0 {
+			t := clk.NewTimer(a.Delay)
+			select {
+			case <-t.C():
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			}
+		}
+		prev = a.Delay
+	}
+}
+
+// Permanent marks err as non-retryable: Retry stops and returns err.
+// Permanent(nil) returns nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
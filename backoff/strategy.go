@@ -0,0 +1,126 @@
+package backoff
+
+import (
+	"math"
+	"time"
+
+	"github.com/trickstertwo/xclock/internal/splitmix"
+)
+
+// Strategy computes the delay before the next attempt.
+// attempt is the number of failed attempts so far (1 after the first failure);
+// prev is the delay used before the failed attempt (0 for the first).
+// Implementations must be safe for concurrent use.
+type Strategy interface {
+	Delay(attempt int, prev time.Duration) time.Duration
+}
+
+// Constant waits the same interval between attempts.
+func Constant(interval time.Duration) Strategy { return constant(interval) }
+
+type constant time.Duration
+
+func (c constant) Delay(int, time.Duration) time.Duration { return time.Duration(c) }
+
+// ExponentialConfig configures an exponential strategy.
+type ExponentialConfig struct {
+	// Initial is the first delay. Defaults to 100ms.
+	Initial time.Duration
+	// Multiplier grows the delay per attempt. Values <= 1 default to 2.
+	Multiplier float64
+	// Max caps the delay (before jitter). 0 means uncapped.
+	Max time.Duration
+	// Jitter randomizes each delay by ±Jitter×delay, in [0, 1]. 0 disables it.
+	Jitter float64
+	// Seed initializes the jitter PRNG. If 0, it's seeded from time.Now().UnixNano().
+	Seed uint64
+}
+
+// NewExponential returns Initial × Multiplier^(attempt-1), capped at Max, with
+// optional proportional jitter.
+func NewExponential(cfg ExponentialConfig) Strategy {
+	if cfg.Initial <= 0 {
+		cfg.Initial = 100 * time.Millisecond
+	}
+	if cfg.Multiplier <= 1 {
+		cfg.Multiplier = 2
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.Jitter > 1 {
+		cfg.Jitter = 1
+	}
+	return &exponential{cfg: cfg, rng: seeded(cfg.Seed)}
+}
+
+type exponential struct {
+	cfg ExponentialConfig
+	rng *splitmix.Rand
+}
+
+func (e *exponential) Delay(attempt int, _ time.Duration) time.Duration {
+	limit := float64(math.MaxInt64)
+	if e.cfg.Max > 0 {
+		limit = float64(e.cfg.Max)
+	}
+	d := float64(e.cfg.Initial) * math.Pow(e.cfg.Multiplier, float64(attempt-1))
+	if d > limit || math.IsInf(d, 0) || math.IsNaN(d) {
+		d = limit
+	}
+	if e.cfg.Jitter > 0 {
+		d += d * e.cfg.Jitter * (2*e.rng.Float64() - 1)
+	}
+	if d >= float64(math.MaxInt64) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// DecorrelatedConfig configures a decorrelated-jitter strategy.
+type DecorrelatedConfig struct {
+	// Base is the minimum delay. Defaults to 100ms.
+	Base time.Duration
+	// Max caps the delay. 0 means uncapped.
+	Max time.Duration
+	// Seed initializes the PRNG. If 0, it's seeded from time.Now().UnixNano().
+	Seed uint64
+}
+
+// NewDecorrelatedJitter returns the "decorrelated jitter" strategy:
+// delay = min(Max, random in [Base, 3×prev]). It spreads retries of many
+// clients better than jittered exponential backoff.
+func NewDecorrelatedJitter(cfg DecorrelatedConfig) Strategy {
+	if cfg.Base <= 0 {
+		cfg.Base = 100 * time.Millisecond
+	}
+	return &decorrelated{cfg: cfg, rng: seeded(cfg.Seed)}
+}
+
+type decorrelated struct {
+	cfg DecorrelatedConfig
+	rng *splitmix.Rand
+}
+
+func (d *decorrelated) Delay(_ int, prev time.Duration) time.Duration {
+	lo := d.cfg.Base
+	hi := prev * 3
+	if prev > math.MaxInt64/3 {
+		hi = math.MaxInt64
+	}
+	out := lo
+	if hi > lo {
+		out = lo + time.Duration(d.rng.Uint64()%uint64(hi-lo+1))
+	}
+	if d.cfg.Max > 0 && out > d.cfg.Max {
+		out = d.cfg.Max
+	}
+	return out
+}
+
+func seeded(seed uint64) *splitmix.Rand {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	return splitmix.New(seed)
+}
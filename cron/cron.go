@@ -0,0 +1,361 @@
+// Package cron runs jobs on cron schedules driven by an injected xclock.Clock.
+//
+// Activations are armed as wall-clock deadlines (xclock.TimerAt), so they follow
+// offset and calibration changes of the clock. Tests drive schedules by injecting
+// a controllable clock instead of waiting on real time.
+//
+//	s := cron.New(cron.Config{Clock: clk, Location: time.UTC})
+//	_, err := s.Add("*/5 * * * *", func(ctx context.Context) error { return flush(ctx) })
+//	s.Start()
+//	defer s.Stop()
+package cron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/internal/splitmix"
+)
+
+// Job is the unit of work run on each activation. The context is cancelled by Stop.
+type Job func(ctx context.Context) error
+
+// ID identifies a scheduled job.
+type ID uint64
+
+// OverlapPolicy decides what happens when an activation arrives while the
+// previous run of the same job is still in progress.
+type OverlapPolicy int
+
+const (
+	// OverlapAllow starts the new run concurrently.
+	OverlapAllow OverlapPolicy = iota
+	// OverlapSkip drops the activation and reports it via Hooks.Skipped.
+	OverlapSkip
+	// OverlapQueue runs the activation after the current run finishes.
+	OverlapQueue
+)
+
+// Run describes a single activation, passed to hooks.
+type Run struct {
+	ID   ID
+	Name string
+	// Scheduled is the nominal activation time (before jitter).
+	Scheduled time.Time
+	// Started and Finished are read from the scheduler clock; zero until known.
+	Started  time.Time
+	Finished time.Time
+}
+
+// Hooks observe job runs. Nil hooks are skipped. Before and After run
+// synchronously on the job's goroutine; Skipped runs on a goroutine of its own.
+// None runs on the scheduler's timer, and Stop waits for them. Keep them fast.
+type Hooks struct {
+	Before  func(r Run)
+	After   func(r Run, err error)
+	Skipped func(r Run)
+}
+
+// Config configures a Scheduler.
+type Config struct {
+	// Clock drives the scheduler. If nil, xclock.Default() is used.
+	Clock xclock.Clock
+	// Location evaluates specs without a CRON_TZ prefix. If nil, time.Local is used.
+	Location *time.Location
+	// Jitter spreads activations by a random delay in [0, Jitter). Per-job
+	// JobConfig.Jitter overrides it.
+	Jitter time.Duration
+	// Seed initializes the jitter PRNG. If 0, it's seeded from the clock.
+	Seed uint64
+	// Hooks apply to every job, before the job's own hooks.
+	Hooks Hooks
+}
+
+// JobConfig configures a single job.
+type JobConfig struct {
+	Name    string
+	Overlap OverlapPolicy
+	// Jitter overrides Config.Jitter when > 0.
+	Jitter time.Duration
+	Hooks  Hooks
+}
+
+// Entry is a snapshot of a scheduled job.
+type Entry struct {
+	ID       ID
+	Name     string
+	Schedule Schedule
+	// Next is the next nominal activation; zero if the schedule is exhausted.
+	Next time.Time
+	// Prev is the nominal time of the last activation; zero if none yet.
+	Prev time.Time
+}
+
+// Scheduler runs jobs on their schedules. Safe for concurrent use.
+type Scheduler struct {
+	clock xclock.Clock
+	loc   *time.Location
+	cfg   Config
+	rng   splitmix.Rand
+
+	mu      sync.Mutex
+	entries map[ID]*entry
+	nextID  ID
+	running bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type entry struct {
+	id    ID
+	sched Schedule
+	cfg   JobConfig
+	job   Job
+
+	next, prev time.Time
+	jitter     time.Duration // applied to the armed activation
+	stop       xclock.CancelFunc
+
+	active int // runs in progress
+	queued int // pending runs under OverlapQueue
+}
+
+// New constructs a Scheduler. No goroutines run until Start.
+func New(cfg Config) *Scheduler {
+	if cfg.Clock == nil {
+		cfg.Clock = xclock.Default()
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = uint64(cfg.Clock.Now().UnixNano())
+	}
+	s := &Scheduler{
+		clock:   cfg.Clock,
+		loc:     cfg.Location,
+		cfg:     cfg,
+		entries: make(map[ID]*entry),
+	}
+	s.rng.Seed(seed)
+	return s
+}
+
+// Add schedules job on spec with default JobConfig.
+func (s *Scheduler) Add(spec string, job Job) (ID, error) {
+	return s.AddJob(spec, JobConfig{Name: spec}, job)
+}
+
+// AddJob parses spec in the scheduler's location and schedules job with cfg.
+func (s *Scheduler) AddJob(spec string, cfg JobConfig, job Job) (ID, error) {
+	sched, err := ParseIn(spec, s.loc)
+	if err != nil {
+		return 0, err
+	}
+	return s.Schedule(sched, cfg, job), nil
+}
+
+// Schedule adds job on an already-built Schedule. If the scheduler is running,
+// the job is armed immediately.
+func (s *Scheduler) Schedule(sched Schedule, cfg JobConfig, job Job) ID {
+	if sched == nil || job == nil {
+		panic("cron: Schedule with nil schedule or job")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	e := &entry{id: s.nextID, sched: sched, cfg: cfg, job: job}
+	s.entries[e.id] = e
+	if s.running {
+		e.next = sched.Next(s.clock.Now())
+		s.armLocked(e)
+	}
+	return e.id
+}
+
+// Remove unschedules a job. Runs already in progress are not interrupted.
+func (s *Scheduler) Remove(id ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[id]; ok {
+		if e.stop != nil {
+			e.stop()
+		}
+		delete(s.entries, id)
+	}
+}
+
+// Start arms all jobs. Calling Start on a running scheduler is a no-op.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	now := s.clock.Now()
+	for _, e := range s.entries {
+		e.next = e.sched.Next(now)
+		s.armLocked(e)
+	}
+}
+
+// Stop disarms all jobs, cancels the context passed to running jobs and waits
+// for them and any pending hooks to return. Safe to call multiple times.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	for _, e := range s.entries {
+		if e.stop != nil {
+			e.stop()
+			e.stop = nil
+		}
+		e.queued = 0
+	}
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Entries returns a snapshot of all scheduled jobs.
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, Entry{ID: e.id, Name: e.cfg.Name, Schedule: e.sched, Next: e.next, Prev: e.prev})
+	}
+	return out
+}
+
+// Preview returns the next n nominal activations of job id from the clock's Now().
+func (s *Scheduler) Preview(id ID, n int) ([]time.Time, error) {
+	s.mu.Lock()
+	e, ok := s.entries[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("cron: unknown job %d", id)
+	}
+	return NextN(e.sched, s.clock.Now(), n), nil
+}
+
+func (s *Scheduler) armLocked(e *entry) {
+	if e.next.IsZero() {
+		e.stop = nil
+		return
+	}
+	e.jitter = s.jitterFor(e)
+	next := e.next
+	e.stop = xclock.FuncAt(s.clock, next.Add(e.jitter), func() { s.fire(e, next) })
+}
+
+func (s *Scheduler) jitterFor(e *entry) time.Duration {
+	max := e.cfg.Jitter
+	if max <= 0 {
+		max = s.cfg.Jitter
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(s.rng.Uint64() % uint64(max))
+}
+
+// fire handles an activation and arms the following one.
+func (s *Scheduler) fire(e *entry, nominal time.Time) {
+	s.mu.Lock()
+	if !s.running || s.entries[e.id] != e || !e.next.Equal(nominal) {
+		s.mu.Unlock()
+		return
+	}
+	e.prev = nominal
+	// Schedule from the nominal time, skipping activations missed while late.
+	from := nominal
+	if late := s.clock.Now().Add(-e.jitter); late.After(from) {
+		from = late
+	}
+	e.next = e.sched.Next(from)
+	s.armLocked(e)
+
+	r := Run{ID: e.id, Name: e.cfg.Name, Scheduled: nominal}
+	start, skipped := true, false
+	if e.active > 0 {
+		switch e.cfg.Overlap {
+		case OverlapSkip:
+			start, skipped = false, true
+		case OverlapQueue:
+			start = false
+			e.queued++ // picked up by the running worker
+		}
+	}
+	if start {
+		e.active++
+		s.wg.Add(1)
+	}
+	if skipped {
+		s.wg.Add(1)
+	}
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	if start {
+		go s.run(ctx, e, r)
+	}
+	if skipped {
+		go s.skip(e, r)
+	}
+}
+
+// skip reports a skipped activation off the timer goroutine.
+func (s *Scheduler) skip(e *entry, r Run) {
+	defer s.wg.Done()
+	s.hooks(e, func(h Hooks) {
+		if h.Skipped != nil {
+			h.Skipped(r)
+		}
+	})
+}
+
+// run executes the job, then drains queued activations.
+func (s *Scheduler) run(ctx context.Context, e *entry, r Run) {
+	defer s.wg.Done()
+	for {
+		r.Started = s.clock.Now()
+		s.hooks(e, func(h Hooks) {
+			if h.Before != nil {
+				h.Before(r)
+			}
+		})
+		err := e.job(ctx)
+		r.Finished = s.clock.Now()
+		s.hooks(e, func(h Hooks) {
+			if h.After != nil {
+				h.After(r, err)
+			}
+		})
+
+		s.mu.Lock()
+		if e.queued == 0 || ctx.Err() != nil {
+			e.active--
+			s.mu.Unlock()
+			return
+		}
+		e.queued--
+		r = Run{ID: e.id, Name: e.cfg.Name, Scheduled: e.prev}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) hooks(e *entry, call func(Hooks)) {
+	call(s.cfg.Hooks)
+	call(e.cfg.Hooks)
+}
@@ -0,0 +1,231 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses a cron expression into a Schedule.
+//
+// Accepted forms:
+//   - 5 fields: minute hour day-of-month month day-of-week
+//   - 6 fields: second minute hour day-of-month month day-of-week
+//   - descriptors: @yearly (@annually), @monthly, @weekly, @daily (@midnight),
+//     @hourly, and @every <duration> (e.g. "@every 1m30s")
+//   - an optional "CRON_TZ=<zone> " or "TZ=<zone> " prefix selecting the location
+//
+// Fields accept *, ?, lists (1,5), ranges (1-5), steps (*/15, 10-40/5, 5/10) and,
+// for month and day-of-week, three-letter names (JAN, MON). Day-of-week 7 is Sunday.
+func Parse(spec string) (Schedule, error) {
+	return ParseIn(spec, nil)
+}
+
+// ParseIn is Parse with a default location for specs without a TZ prefix.
+// A nil loc evaluates the schedule in the location of the time passed to Next.
+func ParseIn(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("cron: empty spec")
+	}
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexByte(spec, ' ')
+		if i < 0 {
+			return nil, fmt.Errorf("cron: missing fields after time zone in %q", spec)
+		}
+		name := spec[strings.IndexByte(spec, '=')+1 : i]
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("cron: bad time zone %q: %w", name, err)
+		}
+		loc = l
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		return parseDescriptor(spec, loc)
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, found %d in %q", len(fields), spec)
+	}
+
+	s := &SpecSchedule{Location: loc}
+	var err error
+	if s.Second, _, err = parseField(fields[0], seconds); err != nil {
+		return nil, err
+	}
+	if s.Minute, _, err = parseField(fields[1], minutes); err != nil {
+		return nil, err
+	}
+	if s.Hour, _, err = parseField(fields[2], hours); err != nil {
+		return nil, err
+	}
+	s.hourInterval = isInterval(fields[2])
+	if s.Dom, s.domStar, err = parseField(fields[3], dom); err != nil {
+		return nil, err
+	}
+	if s.Month, _, err = parseField(fields[4], months); err != nil {
+		return nil, err
+	}
+	if s.Dow, s.dowStar, err = parseField(fields[5], dow); err != nil {
+		return nil, err
+	}
+	// Day-of-week 7 is an alias for Sunday.
+	if s.Dow&(1<<7) != 0 {
+		s.Dow = s.Dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// MustParse is like Parse but panics on error. Intended for static specs.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseDescriptor(spec string, loc *time.Location) (Schedule, error) {
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("cron: bad @every duration %q: %w", rest, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("cron: @every duration must be positive, got %s", d)
+		}
+		return Every(d), nil
+	}
+	var expr string
+	switch spec {
+	case "@yearly", "@annually":
+		expr = "0 0 0 1 1 *"
+	case "@monthly":
+		expr = "0 0 0 1 * *"
+	case "@weekly":
+		expr = "0 0 0 * * 0"
+	case "@daily", "@midnight":
+		expr = "0 0 0 * * *"
+	case "@hourly":
+		expr = "0 0 * * * *"
+	default:
+		return nil, fmt.Errorf("cron: unknown descriptor %q", spec)
+	}
+	return ParseIn(expr, loc)
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	seconds = bounds{name: "second", min: 0, max: 59}
+	minutes = bounds{name: "minute", min: 0, max: 59}
+	hours   = bounds{name: "hour", min: 0, max: 23}
+	dom     = bounds{name: "day-of-month", min: 1, max: 31}
+	months  = bounds{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dow = bounds{name: "day-of-week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// parseField returns the bitset for a comma-separated field and whether it was
+// an unrestricted wildcard (* or ?).
+func parseField(field string, b bounds) (bits uint64, star bool, err error) {
+	star = true
+	for _, part := range strings.Split(field, ",") {
+		pb, pstar, err := parseRange(part, b)
+		if err != nil {
+			return 0, false, err
+		}
+		bits |= pb
+		star = star && pstar
+	}
+	return bits, star, nil
+}
+
+// isInterval reports whether every part of field is a wildcard or a step, i.e.
+// the field describes an interval rather than fixed values.
+func isInterval(field string) bool {
+	for _, part := range strings.Split(field, ",") {
+		rng, _, hasStep := strings.Cut(part, "/")
+		if !hasStep && rng != "*" && rng != "?" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRange(part string, b bounds) (uint64, bool, error) {
+	rng, stepStr, hasStep := strings.Cut(part, "/")
+	lo, hi := b.min, b.max
+	star := false
+
+	switch {
+	case rng == "*" || rng == "?":
+		star = !hasStep
+	case strings.Contains(rng, "-"):
+		l, h, _ := strings.Cut(rng, "-")
+		var err error
+		if lo, err = parseValue(l, b); err != nil {
+			return 0, false, err
+		}
+		if hi, err = parseValue(h, b); err != nil {
+			return 0, false, err
+		}
+	default:
+		v, err := parseValue(rng, b)
+		if err != nil {
+			return 0, false, err
+		}
+		lo = v
+		if !hasStep {
+			hi = v // "5/10" means 5 to max, every 10
+		}
+	}
+
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepStr, 10, 8)
+		if err != nil || n == 0 {
+			return 0, false, fmt.Errorf("cron: bad step %q in %s field", stepStr, b.name)
+		}
+		step = uint(n)
+	}
+	if lo > hi {
+		return 0, false, fmt.Errorf("cron: range %q is backwards in %s field", part, b.name)
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << v
+	}
+	return bits, star, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("cron: bad value %q in %s field", s, b.name)
+	}
+	if uint(n) < b.min || uint(n) > b.max {
+		return 0, fmt.Errorf("cron: %s value %d out of range [%d, %d]", b.name, n, b.min, b.max)
+	}
+	return uint(n), nil
+}
@@ -0,0 +1,222 @@
+package cron
+
+import (
+	"slices"
+	"time"
+)
+
+// Schedule computes activation times. Next returns the first activation strictly
+// after t, or the zero time if there is none.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// NextN previews the next n activations of s after t.
+func NextN(s Schedule, t time.Time, n int) []time.Time {
+	out := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// Every is a fixed-interval schedule (the @every descriptor).
+// It is independent of time zones and DST.
+type Every time.Duration
+
+func (e Every) Next(t time.Time) time.Time {
+	if e <= 0 {
+		return time.Time{}
+	}
+	return t.Add(time.Duration(e))
+}
+
+// SpecSchedule is a parsed cron expression. Each field is a bitset of allowed values.
+//
+// Matching happens on wall-clock fields in Location:
+//   - A time skipped by a spring-forward transition runs once, at the end of the gap.
+//   - A time repeated by a fall-back transition runs once, at its first occurrence,
+//     when the hour field is fixed (e.g. "30 1 * * *").
+//   - Interval specs, whose hour field is a wildcard or a step (e.g. "*/15 * * * *",
+//     "0 */2 * * *"), keep firing through the repeated hour, matching both
+//     occurrences, as Vixie cron and systemd do.
+type SpecSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+	// Location to evaluate fields in. Nil means the location of the time passed to Next.
+	Location *time.Location
+
+	domStar, dowStar bool
+	// hourInterval is set by Parse for a wildcard or stepped hour field.
+	hourInterval bool
+}
+
+// searchYears bounds the search for impossible specs (e.g. Feb 30).
+const searchYears = 5
+
+func (s *SpecSchedule) Next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = t.Location()
+	}
+	after := t
+	// Search in civil time: UTC fields mirror wall-clock fields in loc, so the
+	// field walk below never sees DST. resolve maps candidates back to instants.
+	c := civil(t.In(loc))
+	var next time.Time
+	for {
+		c = s.nextCivil(c)
+		if c.IsZero() {
+			break
+		}
+		if inst, ok := resolve(c, loc, after, s.hourInterval); ok {
+			next = inst
+			break
+		}
+	}
+	if s.hourInterval {
+		// The civil walk cannot go back to the repeated wall times when t is in
+		// their first occurrence; look for a match in the second one.
+		if inst, ok := s.repeated(t.In(loc)); ok && (next.IsZero() || inst.Before(next)) {
+			next = inst
+		}
+	}
+	if next.IsZero() {
+		return time.Time{}
+	}
+	return next.In(t.Location())
+}
+
+// repeated returns the first match in the second occurrence of the wall times
+// repeated by the backward transition ending t's zone period, if t lies in their
+// first occurrence.
+func (s *SpecSchedule) repeated(t time.Time) (time.Time, bool) {
+	_, end := t.ZoneBounds()
+	if end.IsZero() {
+		return time.Time{}, false
+	}
+	_, cur := t.Zone()
+	_, nxt := end.Zone()
+	d := time.Duration(cur-nxt) * time.Second
+	if d <= 0 || t.Before(end.Add(-d)) {
+		return time.Time{}, false
+	}
+	from := civil(end)
+	c := s.nextCivil(from.Add(-time.Second))
+	if c.IsZero() || !c.Before(from.Add(d)) {
+		return time.Time{}, false
+	}
+	return end.Add(c.Sub(from)), true
+}
+
+// nextCivil returns the first civil time strictly after c matching all fields.
+func (s *SpecSchedule) nextCivil(c time.Time) time.Time {
+	c = c.Add(time.Second)
+	limit := c.Year() + searchYears
+
+wrap:
+	if c.Year() > limit {
+		return time.Time{}
+	}
+	for s.Month&(1<<uint(c.Month())) == 0 {
+		c = time.Date(c.Year(), c.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		if c.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(c) {
+		c = time.Date(c.Year(), c.Month(), c.Day()+1, 0, 0, 0, 0, time.UTC)
+		if c.Day() == 1 {
+			goto wrap
+		}
+	}
+	for s.Hour&(1<<uint(c.Hour())) == 0 {
+		c = time.Date(c.Year(), c.Month(), c.Day(), c.Hour()+1, 0, 0, 0, time.UTC)
+		if c.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for s.Minute&(1<<uint(c.Minute())) == 0 {
+		c = time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute()+1, 0, 0, time.UTC)
+		if c.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for s.Second&(1<<uint(c.Second())) == 0 {
+		c = c.Add(time.Second)
+		if c.Second() == 0 {
+			goto wrap
+		}
+	}
+	return c
+}
+
+// dayMatches applies the classic cron rule: when both day-of-month and
+// day-of-week are restricted, either may match; otherwise both must.
+func (s *SpecSchedule) dayMatches(c time.Time) bool {
+	dom := s.Dom&(1<<uint(c.Day())) != 0
+	dow := s.Dow&(1<<uint(c.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// resolve maps civil time c to an instant in loc strictly after `after`. A wall
+// time repeated by a backward transition resolves to its first occurrence, or,
+// with both set, to the first occurrence after `after`.
+func resolve(c time.Time, loc *time.Location, after time.Time, both bool) (time.Time, bool) {
+	inst := time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute(), c.Second(), 0, loc)
+	if !sameWall(inst, c) {
+		// Skipped by a forward transition: run when the new zone period starts.
+		// time.Date may normalize to either side of the gap; pick the boundary
+		// that lies between the two.
+		start, end := inst.ZoneBounds()
+		if civil(inst).Before(c) {
+			inst = end
+		} else {
+			inst = start
+		}
+		return inst, inst.After(after)
+	}
+	// A repeated wall time has one occurrence per offset in effect around it;
+	// time.Date picks either. Collect both, earliest first.
+	occ := []time.Time{inst}
+	_, off := inst.Zone()
+	for _, near := range []time.Time{inst.Add(-12 * time.Hour), inst.Add(12 * time.Hour)} {
+		_, o := near.Zone()
+		if o == off {
+			continue
+		}
+		if alt := inst.Add(time.Duration(off-o) * time.Second); sameWall(alt.In(loc), c) {
+			occ = append(occ, alt)
+		}
+	}
+	slices.SortFunc(occ, time.Time.Compare)
+	if !both {
+		// Never run the second occurrence.
+		return occ[0], occ[0].After(after)
+	}
+	for _, o := range occ {
+		if o.After(after) {
+			return o, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func sameWall(inst, c time.Time) bool {
+	y, m, d := inst.Date()
+	h, mi, s := inst.Clock()
+	cy, cm, cd := c.Date()
+	ch, cmi, cs := c.Clock()
+	return y == cy && m == cm && d == cd && h == ch && mi == cmi && s == cs
+}
+
+// civil returns t's wall-clock fields (truncated to seconds) as a UTC time.
+func civil(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
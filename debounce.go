@@ -0,0 +1,203 @@
+package xclock
+
+import (
+	"sync"
+	"time"
+)
+
+// DebounceOptions selects which edges of a burst invoke fn.
+// If neither Leading nor Trailing is set, Trailing is used.
+type DebounceOptions struct {
+	// Leading invokes fn on the first call of a burst.
+	Leading bool
+	// Trailing invokes fn once the burst has been quiet for the wait duration.
+	Trailing bool
+	// MaxWait bounds how long fn may be delayed under continuous calls. 0 disables it.
+	MaxWait time.Duration
+}
+
+// Coalescer collapses bursts of Call into fewer invocations of fn.
+// It is returned by Debounce and Throttle and is safe for concurrent use.
+//
+// fn runs on the calling goroutine for leading edges and Flush, and on a timer
+// goroutine for trailing edges. It never runs while internal locks are held, so
+// fn may call back into the Coalescer.
+type Coalescer struct {
+	clock   Clock
+	wait    time.Duration
+	maxWait time.Duration
+	fn      func()
+	leading bool
+	trail   bool
+
+	mu         sync.Mutex
+	lastCall   time.Time
+	lastInvoke time.Time
+	pending    bool       // a call arrived since the last invocation
+	cancel     CancelFunc // armed timer; nil when idle
+	gen        uint64     // invalidates timers that lost the race with Cancel/Flush
+}
+
+// Debounce returns a Coalescer that invokes fn after calls stop for wait,
+// scheduling its timers on c (xclock.Default() if nil).
+func Debounce(c Clock, wait time.Duration, fn func(), opts DebounceOptions) *Coalescer {
+	if c == nil {
+		c = Default()
+	}
+	if !opts.Leading && !opts.Trailing {
+		opts.Trailing = true
+	}
+	maxWait := opts.MaxWait
+	if maxWait > 0 && maxWait < wait {
+		maxWait = wait
+	}
+	return &Coalescer{
+		clock:   c,
+		wait:    wait,
+		maxWait: maxWait,
+		fn:      fn,
+		leading: opts.Leading,
+		trail:   opts.Trailing,
+	}
+}
+
+// Throttle returns a Coalescer that invokes fn at most once per interval:
+// on the leading edge and, if calls continue, on the trailing edge.
+func Throttle(c Clock, interval time.Duration, fn func()) *Coalescer {
+	return Debounce(c, interval, fn, DebounceOptions{Leading: true, Trailing: true, MaxWait: interval})
+}
+
+// Call records a call, invoking fn now if an edge is due.
+func (co *Coalescer) Call() {
+	co.mu.Lock()
+	now := co.clock.Now()
+	invoking := co.shouldInvoke(now)
+	co.lastCall = now
+	co.pending = true
+
+	run := false
+	if invoking {
+		if co.cancel == nil {
+			// Leading edge of a new burst.
+			co.lastInvoke = now
+			co.armLocked(co.wait)
+			if co.leading {
+				run = co.invokeLocked(now)
+			}
+		} else if co.maxWait > 0 {
+			// MaxWait elapsed under continuous calls.
+			co.armLocked(co.wait)
+			run = co.invokeLocked(now)
+		}
+	}
+	if co.cancel == nil {
+		co.armLocked(co.wait)
+	}
+	co.mu.Unlock()
+
+	if run {
+		co.fn()
+	}
+}
+
+// Flush invokes a pending trailing call immediately instead of waiting.
+func (co *Coalescer) Flush() {
+	co.mu.Lock()
+	if co.cancel == nil {
+		co.mu.Unlock()
+		return
+	}
+	run := co.trailingLocked(co.clock.Now())
+	co.mu.Unlock()
+
+	if run {
+		co.fn()
+	}
+}
+
+// Cancel drops any pending invocation and resets the Coalescer to idle.
+func (co *Coalescer) Cancel() {
+	co.mu.Lock()
+	co.stopLocked()
+	co.pending = false
+	co.lastCall = time.Time{}
+	co.lastInvoke = time.Time{}
+	co.mu.Unlock()
+}
+
+// Pending reports whether a trailing invocation is waiting.
+func (co *Coalescer) Pending() bool {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	return co.cancel != nil && co.pending && co.trail
+}
+
+func (co *Coalescer) shouldInvoke(now time.Time) bool {
+	if co.lastCall.IsZero() {
+		return true
+	}
+	sinceCall := now.Sub(co.lastCall)
+	return sinceCall >= co.wait || sinceCall < 0 ||
+		(co.maxWait > 0 && now.Sub(co.lastInvoke) >= co.maxWait)
+}
+
+func (co *Coalescer) armLocked(d time.Duration) {
+	co.stopLocked()
+	gen := co.gen
+	co.cancel = co.clock.AfterFunc(d, func() { co.expired(gen) })
+}
+
+func (co *Coalescer) stopLocked() {
+	co.gen++
+	if co.cancel != nil {
+		co.cancel()
+		co.cancel = nil
+	}
+}
+
+func (co *Coalescer) expired(gen uint64) {
+	co.mu.Lock()
+	if gen != co.gen {
+		co.mu.Unlock()
+		return
+	}
+	co.cancel = nil
+	now := co.clock.Now()
+	run := false
+	if co.shouldInvoke(now) {
+		run = co.trailingLocked(now)
+	} else {
+		co.armLocked(co.remaining(now))
+	}
+	co.mu.Unlock()
+
+	if run {
+		co.fn()
+	}
+}
+
+func (co *Coalescer) remaining(now time.Time) time.Duration {
+	d := co.wait - now.Sub(co.lastCall)
+	if co.maxWait > 0 {
+		if m := co.maxWait - now.Sub(co.lastInvoke); m < d {
+			d = m
+		}
+	}
+	return d
+}
+
+// trailingLocked ends the burst and reports whether fn should run.
+func (co *Coalescer) trailingLocked(now time.Time) bool {
+	co.stopLocked()
+	if co.trail && co.pending {
+		return co.invokeLocked(now)
+	}
+	co.pending = false
+	return false
+}
+
+func (co *Coalescer) invokeLocked(now time.Time) bool {
+	co.lastInvoke = now
+	co.pending = false
+	return true
+}
@@ -16,6 +16,9 @@ type facadeFns struct {
 	afterFunc func(time.Duration, func()) CancelFunc
 	newTimer  func(time.Duration) Timer
 	newTicker func(time.Duration) Ticker
+
+	newTimerAt  func(time.Time) Timer
+	afterFuncAt func(time.Time, func()) CancelFunc
 }
 
 var fns atomic.Pointer[facadeFns]
@@ -33,6 +36,9 @@ func initFacadeFns() {
 		},
 		newTimer:  func(d time.Duration) Timer { return &stdTimer{t: time.NewTimer(d)} },
 		newTicker: func(d time.Duration) Ticker { return &stdTicker{t: time.NewTicker(d)} },
+
+		newTimerAt:  standardSystemClock.NewTimerAt,
+		afterFuncAt: standardSystemClock.AfterFuncAt,
 	}
 	fns.Store(sys)
 }
@@ -47,6 +53,13 @@ func updateFacadeFns(c Clock) {
 		afterFunc: c.AfterFunc,
 		newTimer:  c.NewTimer,
 		newTicker: c.NewTicker,
+
+		newTimerAt:  func(t time.Time) Timer { return TimerAt(c, t) },
+		afterFuncAt: func(t time.Time, f func()) CancelFunc { return FuncAt(c, t, f) },
+	}
+	if ws, ok := c.(WallScheduler); ok {
+		g.newTimerAt = ws.NewTimerAt
+		g.afterFuncAt = ws.AfterFuncAt
 	}
 	fns.Store(g)
 }
@@ -64,3 +77,10 @@ func AfterFunc(d time.Duration, f func()) CancelFunc {
 }
 func NewTimer(d time.Duration) Timer   { return fns.Load().newTimer(d) }
 func NewTicker(d time.Duration) Ticker { return fns.Load().newTicker(d) }
+
+// Wall-time facade: fire at an instant of the default clock's Now().
+
+func NewTimerAt(t time.Time) Timer { return fns.Load().newTimerAt(t) }
+func AfterFuncAt(t time.Time, f func()) CancelFunc {
+	return fns.Load().afterFuncAt(t, f)
+}
@@ -1,3 +1,5 @@
 module github.com/trickstertwo/xclock
 
 go 1.25
+
+require golang.org/x/sys v0.36.0
@@ -0,0 +1,40 @@
+// Package splitmix provides the seeded PRNG shared by jitter, chaos, drift,
+// backoff and cron.
+package splitmix
+
+import "sync/atomic"
+
+// Rand is a lock-free SplitMix64 PRNG (one atomic add per draw).
+// A given seed always produces the same sequence of draws; which goroutine
+// receives which draw still depends on scheduling.
+// The zero value is usable and starts from seed 0.
+type Rand struct {
+	state atomic.Uint64
+}
+
+// New returns a generator seeded with seed.
+func New(seed uint64) *Rand {
+	r := &Rand{}
+	r.state.Store(seed)
+	return r
+}
+
+// Seed resets the generator state.
+func (r *Rand) Seed(seed uint64) { r.state.Store(seed) }
+
+// Uint64 returns the next pseudo-random value.
+func (r *Rand) Uint64() uint64 {
+	// SplitMix64: increment then mix
+	z := r.state.Add(0x9e3779b97f4a7c15)
+	z ^= z >> 30
+	z *= 0xbf58476d1ce4e5b9
+	z ^= z >> 27
+	z *= 0x94d049bb133111eb
+	z ^= z >> 31
+	return z
+}
+
+// Float64 returns a pseudo-random value in [0, 1).
+func (r *Rand) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
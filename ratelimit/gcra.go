@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// GCRAConfig configures a GCRA limiter.
+type GCRAConfig struct {
+	// Clock drives the limiter. If nil, xclock.Default() is used.
+	Clock xclock.Clock
+	// Rate is the sustained rate in events per second. Must be > 0.
+	Rate float64
+	// Burst is the number of events admitted back-to-back. Values < 1 are treated as 1.
+	Burst int
+}
+
+// GCRA implements the Generic Cell Rate Algorithm. It admits the same traffic as a
+// token bucket but keeps a single timestamp (the theoretical arrival time) as state.
+type GCRA struct {
+	limiter
+
+	mu       sync.Mutex
+	interval time.Duration // emission interval: one event every interval
+	burst    int
+	tat      time.Time // theoretical arrival time
+}
+
+// NewGCRA constructs a GCRA limiter. It panics if cfg.Rate <= 0.
+func NewGCRA(cfg GCRAConfig) *GCRA {
+	if cfg.Rate <= 0 {
+		panic("ratelimit: NewGCRA with non-positive Rate")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = xclock.Default()
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	g := &GCRA{interval: durationOf(1 / cfg.Rate), burst: cfg.Burst}
+	g.limiter = limiter{clock: cfg.Clock, r: g}
+	return g
+}
+
+func (g *GCRA) capacity() int { return g.burst }
+
+func (g *GCRA) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, func(), bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n > g.burst {
+		return time.Time{}, nil, false
+	}
+	tat := g.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	cost := time.Duration(n) * g.interval
+	newTat := tat.Add(cost)
+	// Events may arrive up to burst intervals ahead of the theoretical schedule.
+	act := newTat.Add(-time.Duration(g.burst) * g.interval)
+	if act.Before(now) {
+		act = now
+	}
+	if act.Sub(now) > maxWait {
+		return time.Time{}, nil, false
+	}
+	g.tat = newTat
+	undo := func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if !act.After(g.clock.Now()) {
+			return // the events may have happened
+		}
+		// Later reservations were scheduled behind this one; only the part of its
+		// cost they have not built on is returned.
+		if refund := cost - g.tat.Sub(newTat); refund > 0 {
+			g.tat = g.tat.Add(-refund)
+		}
+	}
+	return act, undo, true
+}
@@ -0,0 +1,163 @@
+// Package ratelimit provides rate limiters driven by an injected xclock.Clock.
+//
+// All limiters read time only through their Clock and Wait sleeps on the clock's
+// timers, so a controllable clock drives limiter behavior deterministically.
+//
+//	lim := ratelimit.NewTokenBucket(ratelimit.TokenBucketConfig{Clock: clk, Rate: 100, Burst: 10})
+//	if err := lim.Wait(ctx); err != nil {
+//	    return err
+//	}
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// InfDuration is returned by Reservation.Delay when the reservation failed.
+const InfDuration = time.Duration(math.MaxInt64)
+
+var (
+	// ErrExceedsLimit is returned by WaitN when n can never be admitted at once.
+	ErrExceedsLimit = errors.New("ratelimit: n exceeds limiter burst")
+	// ErrWouldExceedDeadline is returned by WaitN on the system clock when
+	// admission would come after the context deadline.
+	ErrWouldExceedDeadline = errors.New("ratelimit: wait would exceed context deadline")
+)
+
+// Limiter is implemented by every limiter in this package.
+type Limiter interface {
+	// Allow reports whether one event may happen now.
+	Allow() bool
+	// AllowN reports whether n events may happen now.
+	AllowN(n int) bool
+	// Reserve reserves one event, possibly in the future. See ReserveN.
+	Reserve() *Reservation
+	// ReserveN reserves n events and reports when they may happen. The caller
+	// must wait Delay() before acting, or Cancel() to return the capacity.
+	ReserveN(n int) *Reservation
+	// Wait blocks until one event is admitted or ctx is done.
+	Wait(ctx context.Context) error
+	// WaitN blocks until n events are admitted or ctx is done.
+	WaitN(ctx context.Context, n int) error
+}
+
+// Reservation holds admission for events at a future time.
+type Reservation struct {
+	ok     bool
+	act    time.Time
+	clock  xclock.Clock
+	undo   func()
+	cancel sync.Once
+}
+
+// OK reports whether the reservation can ever be honored.
+func (r *Reservation) OK() bool { return r.ok }
+
+// Time returns when the reserved events may happen.
+func (r *Reservation) Time() time.Time { return r.act }
+
+// Delay returns how long to wait before acting, per the limiter's clock.
+// It returns InfDuration if the reservation is not OK.
+func (r *Reservation) Delay() time.Duration { return r.DelayFrom(r.clock.Now()) }
+
+// DelayFrom is Delay measured from now.
+func (r *Reservation) DelayFrom(now time.Time) time.Duration {
+	if !r.ok {
+		return InfDuration
+	}
+	if d := r.act.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Cancel returns the reserved capacity to the limiter if the reserved time has
+// not come yet; after that the events count as used and Cancel does nothing.
+// TokenBucket and GCRA keep the part of the capacity that later reservations
+// were already granted against; SlidingWindow drops this reservation's events.
+// Safe to call multiple times.
+func (r *Reservation) Cancel() {
+	if !r.ok || r.undo == nil {
+		return
+	}
+	r.cancel.Do(r.undo)
+}
+
+// reserver is the per-algorithm core. reserve admits n events at or after now,
+// failing if admission would take longer than maxWait.
+type reserver interface {
+	reserve(now time.Time, n int, maxWait time.Duration) (act time.Time, undo func(), ok bool)
+	// capacity is the largest n that can ever be admitted at once.
+	capacity() int
+}
+
+// limiter implements Limiter on top of a reserver; each algorithm embeds it.
+type limiter struct {
+	clock xclock.Clock
+	r     reserver
+}
+
+func (l *limiter) Allow() bool { return l.AllowN(1) }
+
+func (l *limiter) AllowN(n int) bool {
+	_, _, ok := l.r.reserve(l.clock.Now(), n, 0)
+	return ok
+}
+
+func (l *limiter) Reserve() *Reservation { return l.ReserveN(1) }
+
+func (l *limiter) ReserveN(n int) *Reservation {
+	return l.reserveN(l.clock.Now(), n, InfDuration)
+}
+
+func (l *limiter) reserveN(now time.Time, n int, maxWait time.Duration) *Reservation {
+	act, undo, ok := l.r.reserve(now, n, maxWait)
+	return &Reservation{ok: ok, act: act, clock: l.clock, undo: undo}
+}
+
+func (l *limiter) Wait(ctx context.Context) error { return l.WaitN(ctx, 1) }
+
+func (l *limiter) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := l.clock.Now()
+	maxWait := InfDuration
+	if dl, ok := ctx.Deadline(); ok && l.clock == xclock.System() {
+		// Context deadlines are in real time; only the system clock shares that
+		// timebase. Other clocks rely on ctx.Done() while waiting.
+		maxWait = time.Until(dl)
+	}
+	if n > l.r.capacity() {
+		return ErrExceedsLimit
+	}
+	r := l.reserveN(now, n, maxWait)
+	if !r.ok {
+		return ErrWouldExceedDeadline
+	}
+	d := r.DelayFrom(now)
+	if d == 0 {
+		return nil
+	}
+	t := l.clock.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*GCRA)(nil)
+	_ Limiter = (*SlidingWindow)(nil)
+)
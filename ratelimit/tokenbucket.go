@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// TokenBucketConfig configures a TokenBucket.
+type TokenBucketConfig struct {
+	// Clock drives the limiter. If nil, xclock.Default() is used.
+	Clock xclock.Clock
+	// Rate is the refill rate in tokens per second. Rate <= 0 never refills.
+	Rate float64
+	// Burst is the bucket size. Values < 1 are treated as 1.
+	Burst int
+}
+
+// TokenBucket is a classic token bucket: up to Burst tokens, refilled at Rate.
+// The bucket starts full.
+type TokenBucket struct {
+	limiter
+
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+	latest time.Time // act time of the latest reservation
+}
+
+// NewTokenBucket constructs a full token bucket.
+func NewTokenBucket(cfg TokenBucketConfig) *TokenBucket {
+	if cfg.Clock == nil {
+		cfg.Clock = xclock.Default()
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	tb := &TokenBucket{
+		rate:   cfg.Rate,
+		burst:  cfg.Burst,
+		tokens: float64(cfg.Burst),
+		last:   cfg.Clock.Now(),
+	}
+	tb.limiter = limiter{clock: cfg.Clock, r: tb}
+	return tb
+}
+
+// Tokens returns the tokens available now (negative while reservations are pending).
+func (tb *TokenBucket) Tokens() float64 {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.advance(tb.clock.Now())
+	return tb.tokens
+}
+
+func (tb *TokenBucket) capacity() int { return tb.burst }
+
+func (tb *TokenBucket) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, func(), bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if n > tb.burst {
+		return time.Time{}, nil, false
+	}
+	tb.advance(now)
+
+	left := tb.tokens - float64(n)
+	var wait time.Duration
+	if left < 0 {
+		if tb.rate <= 0 {
+			return time.Time{}, nil, false
+		}
+		wait = durationOf(-left / tb.rate)
+	}
+	if wait > maxWait {
+		return time.Time{}, nil, false
+	}
+	tb.tokens = left
+	act := now.Add(wait)
+	if act.After(tb.latest) {
+		tb.latest = act
+	}
+	undo := func() {
+		tb.mu.Lock()
+		defer tb.mu.Unlock()
+		now := tb.clock.Now()
+		if !act.After(now) {
+			return // the tokens may have been used
+		}
+		// Tokens reserved after this reservation were drawn against its refill
+		// and stay spent.
+		refund := float64(n) - tb.latest.Sub(act).Seconds()*tb.rate
+		if refund <= 0 {
+			return
+		}
+		tb.advance(now)
+		tb.tokens = min(tb.tokens+refund, float64(tb.burst))
+		if tb.latest.Equal(act) {
+			tb.latest = act.Add(-durationOf(float64(n) / tb.rate))
+		}
+	}
+	return act, undo, true
+}
+
+// advance refills tokens for time elapsed since the last update.
+// A clock stepping backwards refills nothing.
+func (tb *TokenBucket) advance(now time.Time) {
+	elapsed := now.Sub(tb.last)
+	if elapsed <= 0 {
+		return
+	}
+	tb.last = now
+	tb.tokens += elapsed.Seconds() * tb.rate
+	if tb.tokens > float64(tb.burst) {
+		tb.tokens = float64(tb.burst)
+	}
+}
+
+// durationOf converts seconds to a Duration, rounding up so callers never act early.
+func durationOf(sec float64) time.Duration {
+	d := time.Duration(sec * float64(time.Second))
+	if float64(d) < sec*float64(time.Second) {
+		d++
+	}
+	return d
+}
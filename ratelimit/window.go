@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+// SlidingWindowConfig configures a SlidingWindow limiter.
+type SlidingWindowConfig struct {
+	// Clock drives the limiter. If nil, xclock.Default() is used.
+	Clock xclock.Clock
+	// Limit is the maximum number of events in any Window. Values < 1 are treated as 1.
+	Limit int
+	// Window is the length of the sliding window. Must be > 0.
+	Window time.Duration
+}
+
+// SlidingWindow is an exact sliding-window-log limiter: it records each admitted
+// event and never admits more than Limit events in any interval of length Window.
+// Memory is O(Limit) plus one entry per reserved event still in the future.
+type SlidingWindow struct {
+	limiter
+
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	log    []time.Time // admitted event times, ascending
+}
+
+// NewSlidingWindow constructs a sliding-window-log limiter. It panics if cfg.Window <= 0.
+func NewSlidingWindow(cfg SlidingWindowConfig) *SlidingWindow {
+	if cfg.Window <= 0 {
+		panic("ratelimit: NewSlidingWindow with non-positive Window")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = xclock.Default()
+	}
+	if cfg.Limit < 1 {
+		cfg.Limit = 1
+	}
+	w := &SlidingWindow{limit: cfg.Limit, window: cfg.Window, log: make([]time.Time, 0, cfg.Limit)}
+	w.limiter = limiter{clock: cfg.Clock, r: w}
+	return w
+}
+
+// Count returns the number of events counted in the window ending now.
+func (w *SlidingWindow) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.prune(w.clock.Now())
+	return len(w.log)
+}
+
+func (w *SlidingWindow) capacity() int { return w.limit }
+
+func (w *SlidingWindow) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, func(), bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if n > w.limit {
+		return time.Time{}, nil, false
+	}
+	w.prune(now)
+
+	// Admit at act >= every logged event, once enough old events have left the
+	// window ending at act. Appending in order keeps every window within Limit.
+	act := now
+	if m := len(w.log); m > 0 {
+		if last := w.log[m-1]; last.After(act) {
+			act = last
+		}
+		if k := m - (w.limit - n); k > 0 {
+			if t := w.log[k-1].Add(w.window); t.After(act) {
+				act = t
+			}
+		}
+	}
+	if act.Sub(now) > maxWait {
+		return time.Time{}, nil, false
+	}
+	for i := 0; i < n; i++ {
+		w.log = append(w.log, act)
+	}
+	undo := func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if !act.After(w.clock.Now()) {
+			return // the events may have happened
+		}
+		removed := 0
+		for i := len(w.log) - 1; i >= 0 && removed < n; i-- {
+			if w.log[i].Equal(act) {
+				w.log = append(w.log[:i], w.log[i+1:]...)
+				removed++
+			}
+		}
+	}
+	return act, undo, true
+}
+
+// prune drops events that fell out of the window ending at now.
+func (w *SlidingWindow) prune(now time.Time) {
+	cut := now.Add(-w.window)
+	i := 0
+	for i < len(w.log) && !w.log[i].After(cut) {
+		i++
+	}
+	if i > 0 {
+		w.log = append(w.log[:0], w.log[i:]...)
+	}
+}
@@ -22,6 +22,16 @@ func (s *systemClock) AfterFunc(d time.Duration, f func()) CancelFunc {
 func (s *systemClock) NewTimer(d time.Duration) Timer   { return &stdTimer{t: time.NewTimer(d)} }
 func (s *systemClock) NewTicker(d time.Duration) Ticker { return &stdTicker{t: time.NewTicker(d)} }
 
+// Wall deadlines: runtime timers are monotonic, so OS clock steps are caught by
+// the periodic re-check in WallTimers.
+
+var systemWall WallTimers
+
+func (s *systemClock) NewTimerAt(t time.Time) Timer { return systemWall.NewTimerAt(s, t) }
+func (s *systemClock) AfterFuncAt(t time.Time, f func()) CancelFunc {
+	return systemWall.AfterFuncAt(s, t, f)
+}
+
 // Adapter types to satisfy our interfaces with minimal overhead.
 
 type stdTicker struct{ t *time.Ticker }
@@ -0,0 +1,310 @@
+package xclock
+
+import (
+	"sync"
+	"time"
+)
+
+// WallScheduler is an optional Clock extension for scheduling against wall-clock
+// instants instead of durations. Implementations re-arm pending deadlines when
+// their wall-to-monotonic relationship changes (offset adjusted, calibration
+// resync), so "fire at 09:00 UTC" fires at the corrected 09:00.
+//
+// Kept separate from Clock so the core interface stays minimal.
+type WallScheduler interface {
+	// NewTimerAt returns a Timer that fires once Now() reaches t.
+	NewTimerAt(t time.Time) Timer
+	// AfterFuncAt runs f once Now() reaches t and returns a CancelFunc.
+	AfterFuncAt(t time.Time, f func()) CancelFunc
+}
+
+// wallRecheck bounds how long a wall timer trusts a single duration-based arm.
+// It catches wall steps the owning clock cannot report (e.g. OS clock steps).
+const wallRecheck = time.Minute
+
+// WallTimers tracks pending wall-clock deadlines on behalf of a Clock.
+// Adapters keep one per clock instance and call Rearm whenever Now() is stepped.
+// The zero value is ready to use.
+type WallTimers struct {
+	mu      sync.Mutex
+	pending map[*wallTimer]struct{}
+}
+
+// NewTimerAt returns a Timer firing once c.Now() reaches t. Each arm is scheduled
+// through c.AfterFunc, so it works over any base (system, frozen, virtual).
+func (w *WallTimers) NewTimerAt(c Clock, t time.Time) Timer {
+	wt := &wallTimer{owner: w, clock: c, ch: make(chan time.Time, 1)}
+	wt.start(t)
+	return wt
+}
+
+// AfterFuncAt runs f in its own goroutine once c.Now() reaches t.
+func (w *WallTimers) AfterFuncAt(c Clock, t time.Time, f func()) CancelFunc {
+	wt := &wallTimer{owner: w, clock: c, fn: f}
+	wt.start(t)
+	return wt.Stop
+}
+
+// NewTicker returns a Ticker whose ticks are spaced d apart in c.Now() time.
+// Each tick is a wall deadline, so Rearm moves the next tick when c is stepped or
+// rescaled. Ticks are dropped for slow receivers, as with time.Ticker.
+func (w *WallTimers) NewTicker(c Clock, d time.Duration) Ticker {
+	if d <= 0 {
+		panic("xclock: non-positive interval for NewTicker")
+	}
+	t := &wallTicker{owner: w, clock: c, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Rearm re-evaluates every pending deadline against the current Now().
+// Call it after stepping the clock; deadlines already passed fire immediately.
+func (w *WallTimers) Rearm() {
+	w.mu.Lock()
+	list := make([]*wallTimer, 0, len(w.pending))
+	for wt := range w.pending {
+		list = append(list, wt)
+	}
+	w.mu.Unlock()
+
+	for _, wt := range list {
+		wt.mu.Lock()
+		if wt.active {
+			wt.armLocked()
+		}
+		wt.mu.Unlock()
+		wt.flush()
+	}
+}
+
+// Pending returns the number of wall deadlines that have not fired or been stopped.
+func (w *WallTimers) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+func (w *WallTimers) track(wt *wallTimer) {
+	w.mu.Lock()
+	if w.pending == nil {
+		w.pending = make(map[*wallTimer]struct{})
+	}
+	w.pending[wt] = struct{}{}
+	w.mu.Unlock()
+}
+
+func (w *WallTimers) untrack(wt *wallTimer) {
+	w.mu.Lock()
+	delete(w.pending, wt)
+	w.mu.Unlock()
+}
+
+type wallTimer struct {
+	owner *WallTimers
+	clock Clock
+	ch    chan time.Time // timer mode
+	fn    func()         // func mode
+
+	mu       sync.Mutex
+	deadline time.Time
+	active   bool
+	gen      uint64     // invalidates stale arms
+	cancel   CancelFunc // current duration-based arm
+	due      bool       // fired under lock; flush delivers outside it
+	firedAt  time.Time
+}
+
+func (wt *wallTimer) start(t time.Time) {
+	wt.mu.Lock()
+	wt.deadline = t
+	wt.active = true
+	wt.owner.track(wt)
+	wt.armLocked()
+	wt.mu.Unlock()
+	wt.flush()
+}
+
+// armLocked schedules the next check, or marks the timer due if t has passed.
+func (wt *wallTimer) armLocked() {
+	wt.gen++
+	if wt.cancel != nil {
+		wt.cancel()
+		wt.cancel = nil
+	}
+	now := wt.clock.Now()
+	d := wt.deadline.Sub(now)
+	if d <= 0 {
+		wt.active = false
+		wt.due = true
+		wt.firedAt = now
+		return
+	}
+	if d > wallRecheck {
+		d = wallRecheck
+	}
+	gen := wt.gen
+	wt.cancel = wt.clock.AfterFunc(d, func() { wt.check(gen) })
+}
+
+func (wt *wallTimer) check(gen uint64) {
+	wt.mu.Lock()
+	if gen == wt.gen && wt.active {
+		wt.cancel = nil
+		wt.armLocked()
+	}
+	wt.mu.Unlock()
+	wt.flush()
+}
+
+// flush delivers a pending fire. It holds the timer lock so a concurrent Reset
+// or Stop either cancels the fire or comes after it (and drains it).
+func (wt *wallTimer) flush() {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	if !wt.due {
+		return
+	}
+	wt.due = false
+	wt.owner.untrack(wt)
+	if wt.fn != nil {
+		go wt.fn()
+		return
+	}
+	select {
+	case wt.ch <- wt.firedAt:
+	default:
+	}
+}
+
+// drainLocked discards a fire delivered but not received, so C() never yields
+// a value from before a Reset or Stop (as time.Timer since Go 1.23).
+func (wt *wallTimer) drainLocked() {
+	if wt.ch == nil {
+		return
+	}
+	select {
+	case <-wt.ch:
+	default:
+	}
+}
+
+func (wt *wallTimer) C() <-chan time.Time { return wt.ch }
+
+// Stop prevents the timer from firing. It returns true if the timer was active.
+func (wt *wallTimer) Stop() bool {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	was := wt.active
+	wt.active = false
+	wt.due = false
+	wt.gen++
+	if wt.cancel != nil {
+		wt.cancel()
+		wt.cancel = nil
+	}
+	wt.drainLocked()
+	wt.owner.untrack(wt)
+	return was
+}
+
+// Reset re-targets the timer to Now()+d. It returns true if the timer was active.
+func (wt *wallTimer) Reset(d time.Duration) bool {
+	wt.mu.Lock()
+	was := wt.active
+	wt.deadline = wt.clock.Now().Add(d)
+	wt.active = true
+	wt.due = false
+	wt.drainLocked()
+	wt.owner.track(wt)
+	wt.armLocked()
+	wt.mu.Unlock()
+	wt.flush()
+	return was
+}
+
+type wallTicker struct {
+	owner *WallTimers
+	clock Clock
+	ch    chan time.Time
+
+	mu     sync.Mutex
+	period time.Duration
+	next   time.Time
+	gen    uint64
+	cancel CancelFunc
+}
+
+func (t *wallTicker) C() <-chan time.Time { return t.ch }
+
+func (t *wallTicker) Stop() {
+	t.mu.Lock()
+	t.gen++
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+	t.mu.Unlock()
+}
+
+func (t *wallTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("xclock: non-positive interval for Ticker.Reset")
+	}
+	t.mu.Lock()
+	select { // drop a tick from before the Reset
+	case <-t.ch:
+	default:
+	}
+	t.period = d
+	t.next = t.clock.Now().Add(d)
+	t.armLocked()
+	t.mu.Unlock()
+}
+
+func (t *wallTicker) armLocked() {
+	t.gen++
+	if t.cancel != nil {
+		t.cancel()
+	}
+	gen := t.gen
+	t.cancel = t.owner.AfterFuncAt(t.clock, t.next, func() { t.tick(gen) })
+}
+
+func (t *wallTicker) tick(gen uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if gen != t.gen {
+		return
+	}
+	now := t.clock.Now()
+	select {
+	case t.ch <- now:
+	default:
+	}
+	// Keep the phase; skip ticks missed while late.
+	t.next = t.next.Add(t.period)
+	if !t.next.After(now) {
+		t.next = now.Add(t.period)
+	}
+	t.armLocked()
+}
+
+// TimerAt returns a Timer on c that fires at wall instant t. It uses c's own
+// WallScheduler when available; otherwise it re-checks c.Now() periodically.
+func TimerAt(c Clock, t time.Time) Timer {
+	if ws, ok := c.(WallScheduler); ok {
+		return ws.NewTimerAt(t)
+	}
+	return fallbackWall.NewTimerAt(c, t)
+}
+
+// FuncAt runs f on c at wall instant t. See TimerAt.
+func FuncAt(c Clock, t time.Time, f func()) CancelFunc {
+	if ws, ok := c.(WallScheduler); ok {
+		return ws.AfterFuncAt(t, f)
+	}
+	return fallbackWall.AfterFuncAt(c, t, f)
+}
+
+// fallbackWall serves clocks that do not implement WallScheduler.
+var fallbackWall WallTimers
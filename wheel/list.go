@@ -0,0 +1,50 @@
+package wheel
+
+import "time"
+
+// entry is a scheduled timer, ticker or AfterFunc. Entries live in intrusive
+// doubly-linked buckets so Stop/Reset are O(1).
+type entry struct {
+	expires uint64 // absolute tick
+	period  uint64 // ticks; 0 for one-shot
+	ch      chan time.Time
+	fn      func()
+
+	prev, next *entry
+	b          *bucket // nil when not scheduled
+}
+
+// remove unlinks e from its bucket.
+func (e *entry) remove() {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next, e.b = nil, nil, nil
+}
+
+// bucket is a circular list with a sentinel head.
+type bucket struct {
+	head entry
+}
+
+func (b *bucket) init() {
+	b.head.next = &b.head
+	b.head.prev = &b.head
+}
+
+func (b *bucket) push(e *entry) {
+	e.b = b
+	e.prev = b.head.prev
+	e.next = &b.head
+	b.head.prev.next = e
+	b.head.prev = e
+}
+
+// pop unlinks and returns the first entry, or nil if the bucket is empty.
+func (b *bucket) pop() *entry {
+	e := b.head.next
+	if e == &b.head {
+		return nil
+	}
+	e.remove()
+	return e
+}
@@ -0,0 +1,342 @@
+// Package wheel implements xclock.Clock's timer methods on a hierarchical timing
+// wheel, for services that keep very large numbers of timers (idle timeouts per
+// connection, request deadlines).
+//
+// One driver goroutine ticks on the base clock and fires all expirations of a tick
+// in one batch. Creating, stopping and resetting timers is O(1) and never touches
+// a runtime timer. Timers fire on tick boundaries: a duration d fires no earlier
+// than d and at most two ticks later (plus driver latency).
+//
+//	w := wheel.New(wheel.Config{Tick: 10 * time.Millisecond})
+//	defer w.Stop()
+//	t := w.NewTimer(30 * time.Second) // per-connection idle timeout
+package wheel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trickstertwo/xclock"
+)
+
+type Config struct {
+	// Base drives the wheel and serves Now/Since. If nil, xclock.Default() is used.
+	Base xclock.Clock
+	// Tick is the wheel resolution. Defaults to 1ms.
+	Tick time.Duration
+	// Slots per level, rounded up to a power of two. Defaults to 64.
+	Slots int
+	// Levels of the hierarchy. Defaults to 6; durations beyond
+	// Tick × Slots^Levels are cascaded from the top level until due.
+	Levels int
+}
+
+// Set sets a wheel clock as the process-wide default and returns a restore
+// function that reverts to the previous default and stops the wheel.
+// Recommended for tests and examples:
+//
+//	restore := wheel.Set(wheel.Config{Tick: 5 * time.Millisecond})
+//	defer restore()
+func Set(cfg Config) (restore func()) {
+	prev := xclock.Default()
+	w := New(cfg)
+	xclock.SetDefault(w)
+	return func() {
+		xclock.SetDefault(prev)
+		w.Stop()
+	}
+}
+
+// Use applies a wheel clock without returning a restore function.
+// Recommended in production mains where you never intend to restore.
+func Use(cfg Config) {
+	xclock.SetDefault(New(cfg))
+}
+
+// With runs fn with a wheel clock active, then restores the previous clock
+// even if fn panics (restore still runs during unwinding).
+func With(cfg Config, fn func()) {
+	restore := Set(cfg)
+	defer restore()
+	fn()
+}
+
+// Clock schedules timers on a hierarchical timing wheel.
+type Clock struct {
+	base xclock.Clock
+	tick time.Duration
+	bits uint
+	mask uint64
+
+	mu      sync.Mutex
+	levels  [][]bucket
+	current uint64 // ticks since start
+	count   int    // scheduled entries
+	started time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// New constructs a wheel clock and starts its driver goroutine. Call Stop to end it.
+func New(cfg Config) *Clock {
+	if cfg.Base == nil {
+		cfg.Base = xclock.Default()
+	}
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Millisecond
+	}
+	if cfg.Slots <= 0 {
+		cfg.Slots = 64
+	}
+	if cfg.Levels <= 0 {
+		cfg.Levels = 6
+	}
+	bits := uint(1)
+	for 1<<bits < cfg.Slots {
+		bits++
+	}
+	// Keep every level addressable in a uint64 tick count.
+	for int(bits)*cfg.Levels > 63 {
+		cfg.Levels--
+	}
+
+	w := &Clock{
+		base:    cfg.Base,
+		tick:    cfg.Tick,
+		bits:    bits,
+		mask:    1<<bits - 1,
+		levels:  make([][]bucket, cfg.Levels),
+		started: cfg.Base.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for i := range w.levels {
+		w.levels[i] = make([]bucket, 1<<bits)
+		for j := range w.levels[i] {
+			w.levels[i][j].init()
+		}
+	}
+	go w.drive()
+	return w
+}
+
+// Stop ends the driver goroutine. Pending timers never fire afterwards.
+// Safe to call multiple times.
+func (w *Clock) Stop() {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// Pending returns the number of scheduled timers, tickers and AfterFuncs.
+func (w *Clock) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// Resolution returns the wheel tick.
+func (w *Clock) Resolution() time.Duration { return w.tick }
+
+func (w *Clock) Now() time.Time                  { return w.base.Now() }
+func (w *Clock) Since(t time.Time) time.Duration { return w.base.Now().Sub(t) }
+func (w *Clock) Sleep(d time.Duration)           { <-w.After(d) }
+func (w *Clock) After(d time.Duration) <-chan time.Time {
+	return w.NewTimer(d).C()
+}
+
+func (w *Clock) AfterFunc(d time.Duration, f func()) xclock.CancelFunc {
+	e := &entry{fn: f}
+	w.schedule(e, d)
+	return func() bool { return w.unschedule(e) }
+}
+
+func (w *Clock) NewTimer(d time.Duration) xclock.Timer {
+	t := &timer{w: w, e: entry{ch: make(chan time.Time, 1)}}
+	w.schedule(&t.e, d)
+	return t
+}
+
+func (w *Clock) NewTicker(d time.Duration) xclock.Ticker {
+	if d <= 0 {
+		panic("wheel: non-positive interval for NewTicker")
+	}
+	t := &ticker{w: w, e: entry{ch: make(chan time.Time, 1)}}
+	t.e.period = w.ticksFor(d)
+	w.schedule(&t.e, d)
+	return t
+}
+
+// ticksFor rounds d up to whole ticks, with a minimum of one.
+func (w *Clock) ticksFor(d time.Duration) uint64 {
+	if d <= 0 {
+		return 1
+	}
+	n := uint64((d + w.tick - 1) / w.tick)
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+func (w *Clock) schedule(e *entry, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if e.b != nil {
+		e.remove()
+		w.count--
+	}
+	// Count from the base clock's tick, which may be ahead of a lagging driver,
+	// plus one: the current tick is already partly elapsed.
+	e.expires = w.nowTickLocked() + 1
+	if d > 0 {
+		e.expires += w.ticksFor(d)
+	}
+	w.insertLocked(e)
+	w.count++
+}
+
+func (w *Clock) unschedule(e *entry) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if e.b == nil {
+		return false
+	}
+	e.remove()
+	w.count--
+	return true
+}
+
+// nowTickLocked returns the tick the base clock is in, never behind the wheel.
+func (w *Clock) nowTickLocked() uint64 {
+	if elapsed := w.base.Since(w.started); elapsed > 0 {
+		if t := uint64(elapsed / w.tick); t > w.current {
+			return t
+		}
+	}
+	return w.current
+}
+
+// insertLocked places e at the lowest level whose span covers its expiry.
+func (w *Clock) insertLocked(e *entry) {
+	delta := e.expires - w.current
+	top := len(w.levels) - 1
+	for lvl := 0; lvl <= top; lvl++ {
+		if delta < 1<<(w.bits*uint(lvl+1)) || lvl == top {
+			at := e.expires
+			if lvl == top && delta >= 1<<(w.bits*uint(lvl+1)) {
+				// Beyond the wheel: park in the farthest top-level slot and
+				// re-evaluate on cascade.
+				at = w.current + 1<<(w.bits*uint(lvl+1)) - 1
+			}
+			w.levels[lvl][(at>>(w.bits*uint(lvl)))&w.mask].push(e)
+			return
+		}
+	}
+}
+
+// advanceLocked moves the wheel one tick and collects due entries.
+func (w *Clock) advanceLocked(due []*entry) []*entry {
+	w.current++
+	// Cascade higher levels whose slot boundary we just crossed.
+	for lvl := 1; lvl < len(w.levels); lvl++ {
+		if w.current&(1<<(w.bits*uint(lvl))-1) != 0 {
+			break
+		}
+		b := &w.levels[lvl][(w.current>>(w.bits*uint(lvl)))&w.mask]
+		for e := b.pop(); e != nil; e = b.pop() {
+			w.insertLocked(e)
+		}
+	}
+	b := &w.levels[0][w.current&w.mask]
+	for e := b.pop(); e != nil; e = b.pop() {
+		if e.expires > w.current {
+			w.insertLocked(e) // parked beyond the wheel, not due yet
+			continue
+		}
+		if e.period > 0 {
+			e.expires = w.current + e.period
+			w.insertLocked(e)
+		} else {
+			w.count--
+		}
+		due = append(due, e)
+	}
+	return due
+}
+
+func (w *Clock) drive() {
+	defer close(w.done)
+	tk := w.base.NewTicker(w.tick)
+	defer tk.Stop()
+	var due []*entry
+	var lastElapsed time.Duration
+	for {
+		select {
+		case <-tk.C():
+		case <-w.stop:
+			return
+		}
+		w.mu.Lock()
+		// Follow the base clock, catching up when ticks were dropped under load.
+		// If the base clock does not advance (frozen), count ticker events instead.
+		target := w.nowTickLocked()
+		if elapsed := w.base.Since(w.started); elapsed <= lastElapsed {
+			target = w.current + 1
+		} else {
+			lastElapsed = elapsed
+		}
+		due = due[:0]
+		for w.current < target {
+			due = w.advanceLocked(due)
+		}
+		w.mu.Unlock()
+
+		if len(due) == 0 {
+			continue
+		}
+		now := w.base.Now()
+		for i, e := range due {
+			if e.fn != nil {
+				go e.fn()
+			} else {
+				select {
+				case e.ch <- now:
+				default:
+				}
+			}
+			due[i] = nil
+		}
+	}
+}
+
+type timer struct {
+	w *Clock
+	e entry
+}
+
+func (t *timer) C() <-chan time.Time { return t.e.ch }
+func (t *timer) Stop() bool          { return t.w.unschedule(&t.e) }
+func (t *timer) Reset(d time.Duration) bool {
+	was := t.w.unschedule(&t.e)
+	t.w.schedule(&t.e, d)
+	return was
+}
+
+type ticker struct {
+	w *Clock
+	e entry
+}
+
+func (t *ticker) C() <-chan time.Time { return t.e.ch }
+func (t *ticker) Stop()               { t.w.unschedule(&t.e) }
+func (t *ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("wheel: non-positive interval for Ticker.Reset")
+	}
+	t.w.mu.Lock()
+	t.e.period = t.w.ticksFor(d)
+	t.w.mu.Unlock()
+	t.w.schedule(&t.e, d)
+}
@@ -0,0 +1,37 @@
+// Package xclocktest provides test helpers for code that uses xclock.
+package xclocktest
+
+import (
+	"testing"
+
+	"github.com/trickstertwo/xclock"
+	"github.com/trickstertwo/xclock/adapter/leakcheck"
+)
+
+// VerifyNoLeaks installs a leakcheck clock over the current default for the rest
+// of the test. When the test ends it restores the previous default and fails the
+// test, listing creation stacks, if any ticker was not stopped or any timer or
+// AfterFunc is still pending.
+//
+//	func TestWorker(t *testing.T) {
+//		xclocktest.VerifyNoLeaks(t)
+//		w := StartWorker()
+//		defer w.Close() // must stop its tickers
+//		...
+//	}
+//
+// Deferred calls in the test run before the check. The returned clock can be
+// inspected earlier with Outstanding.
+func VerifyNoLeaks(t testing.TB) *leakcheck.Clock {
+	t.Helper()
+	prev := xclock.Default()
+	c := leakcheck.New(leakcheck.Config{Base: prev})
+	xclock.SetDefault(c)
+	t.Cleanup(func() {
+		xclock.SetDefault(prev)
+		if err := c.Check(); err != nil {
+			t.Error(err)
+		}
+	})
+	return c
+}